@@ -68,24 +68,18 @@ func makeActions(actions []string) (res []method, err error) {
 
 	res = make([]method, length)
 	for k, mtd := range actions {
+		// name[:verb[,verb...]], e.g. update:put or show:get,head
 		mtdDetail := strings.Split(mtd, ":")
-		for i, v := range mtdDetail {
-			if i == 0 {
-				res[k].Name = service.CapitalizeFirstLetter(v)
-			} else {
-				switch strings.ToLower(v) {
-				case "post":
-					res[k].HTTPMethod = "POST"
-				case "get":
-					res[k].HTTPMethod = "GET"
-				default:
-					err = fmt.Errorf("invalid method: %s", v)
-					return
-				}
-			}
-			if res[k].HTTPMethod == "" {
-				res[k].HTTPMethod = "POST"
+		res[k].Name = service.CapitalizeFirstLetter(mtdDetail[0])
+		res[k].HTTPMethod = "POST"
+		if len(mtdDetail) > 1 {
+			var verbs []string
+			verbs, err = service.ParseHTTPMethods(strings.Join(mtdDetail[1:], ","))
+			if err != nil {
+				err = fmt.Errorf("invalid method in %q: %w", mtd, err)
+				return
 			}
+			res[k].HTTPMethod = strings.Join(verbs, ", ")
 		}
 	}
 	return
@@ -52,7 +52,7 @@ func AddController(controllerTmpl, root, controllerRoute string, actions []strin
 	controllerStructName := service.CapitalizeFirstLetter(name) + "Controller"
 
 	err = template.CreateFile(controllerTmpl,
-		template.ControllerStructNameData{controllerStructName},
+		template.ControllerStructNameData{ControllerStructName: controllerStructName},
 		controllerFilePath,
 	)
 	if err != nil {
@@ -10,7 +10,7 @@ func AddMiddleware(middlewareTmpl string, middlewares []string) {
 		middlewareName := service.CapitalizeFirstLetter(middleware)
 
 		filePath := "lib/middleware/" + middlewareName + ".go"
-		err := template.CreateFile(middlewareTmpl, template.MiddlewareNameData{middlewareName}, filePath)
+		err := template.CreateFile(middlewareTmpl, template.MiddlewareNameData{MiddlewareName: middlewareName}, filePath)
 		if err != nil {
 			service.OutputFatal(err)
 		}
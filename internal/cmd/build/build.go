@@ -17,7 +17,8 @@ import (
 //   - goos:       Target operating system
 //   - goarch:     Target architecture
 //   - isApi:      Flag indicating if building an API application
-func Build(routerTmpl, app, appRoot, apiRoot, version, goos, goarch string, isApi bool) {
+//   - static:     Generate static route bindings instead of the reflection router
+func Build(routerTmpl, app, appRoot, apiRoot, version, goos, goarch string, isApi, static bool) {
 	// Set default application root if not specified
 	if appRoot == "" {
 		var err error
@@ -40,7 +41,7 @@ func Build(routerTmpl, app, appRoot, apiRoot, version, goos, goarch string, isAp
 	buildPath := filepath.Join(appRoot, app)
 	if isApi {
 		buildPath = filepath.Join(filepath.Dir(apiRoot), app)
-		makerouter.MakeRouter(routerTmpl, apiRoot)
+		makerouter.MakeRouter(routerTmpl, apiRoot, static, false)
 	}
 	buildPath = "./" + buildPath
 
@@ -3,10 +3,15 @@ package cmd
 
 import (
 	"embed"
+	"time"
 
 	"github.com/jiajia556/god/internal/cmd/build"
+	"github.com/jiajia556/god/internal/cmd/dev"
 	"github.com/jiajia556/god/internal/cmd/initproject"
+	"github.com/jiajia556/god/internal/cmd/lint"
+	"github.com/jiajia556/god/internal/cmd/listroutes"
 	"github.com/jiajia556/god/internal/cmd/makerouter"
+	"github.com/jiajia556/god/internal/cmd/openapi"
 	"github.com/jiajia556/god/internal/service"
 	"github.com/spf13/cobra"
 )
@@ -26,11 +31,16 @@ var initCmd = &cobra.Command{
 	Use:     "init [project-name]",
 	Short:   "Create a new project",
 	Long:    "Initialize a new project with the specified name and basic structure",
-	Example: "  god init myproject\n  god init example.com/myapp",
+	Example: "  god init myproject\n  god init example.com/myapp\n  god init example.com/myapp --db postgres",
 	Args:    cobra.ExactArgs(1), // Requires exactly 1 argument
 	Run: func(cmd *cobra.Command, args []string) {
 		projectName := args[0]
-		initproject.InitProject(projectName, templateFS)
+		dialect, _ := cmd.Flags().GetString("db")
+		dialect, err := service.ParseDialect(dialect)
+		if err != nil {
+			service.OutputFatal(err)
+		}
+		initproject.InitProject(projectName, dialect, templateFS)
 	},
 }
 
@@ -39,17 +49,72 @@ var makeRouterCmd = &cobra.Command{
 	Use:     "mkrt",
 	Short:   "Generate API router configuration",
 	Long:    "Creates or updates the main router file based on existing controllers",
-	Example: "  god mkrt --root api",
+	Example: "  god mkrt --root api\n  god mkrt --static\n  god mkrt --openapi\n  god mkrt --strict\n  god mkrt --watch",
 	Run: func(cmd *cobra.Command, args []string) {
-		// Read router template from embedded files
-		content, err := templateFS.ReadFile("templates/basic/app/api/home/router.go.tmpl")
-		if err != nil {
-			service.OutputFatal(err)
-		}
+		static, _ := cmd.Flags().GetBool("static")
+		strict, _ := cmd.Flags().GetBool("strict")
+		content := readRouterTemplate(static)
 
 		// Get API root path from flag
 		apiRoot, _ := cmd.Flags().GetString("api-root")
-		makerouter.MakeRouter(string(content), apiRoot)
+		withSpec, _ := cmd.Flags().GetBool("openapi")
+		if watch, _ := cmd.Flags().GetBool("watch"); watch {
+			if withSpec {
+				service.OutputFatal("--openapi cannot be used with --watch; run god openapi after the changes")
+			}
+			interval, _ := cmd.Flags().GetDuration("interval")
+			makerouter.WatchRouter(content, apiRoot, static, strict, interval)
+			return
+		}
+		makerouter.MakeRouter(content, apiRoot, static, strict)
+
+		if withSpec {
+			openapi.MakeOpenAPI(apiRoot, "yaml", "")
+		}
+	},
+}
+
+// openapiCmd handles OpenAPI spec generation
+var openapiCmd = &cobra.Command{
+	Use:     "openapi",
+	Short:   "Generate an OpenAPI 3 spec from controllers",
+	Long:    "Writes openapi.yaml (or openapi.json) next to router.go, using @summary, @tag, @request and @response annotations",
+	Example: "  god openapi\n  god openapi --format json\n  god openapi -a app/api/admin --output docs/admin.yaml",
+	Run: func(cmd *cobra.Command, args []string) {
+		apiRoot, _ := cmd.Flags().GetString("api-root")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		openapi.MakeOpenAPI(apiRoot, format, output)
+	},
+}
+
+// lintCmd checks controllers and annotations without generating code
+var lintCmd = &cobra.Command{
+	Use:     "lint",
+	Short:   "Check controller annotations and routes",
+	Long:    "Reports route conflicts, unknown middleware, malformed annotations and methods that cannot be routed, with file:line positions",
+	Example: "  god lint\n  god lint --strict",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		apiRoot, _ := cmd.Flags().GetString("api-root")
+		strict, _ := cmd.Flags().GetBool("strict")
+		lint.Lint(apiRoot, strict)
+	},
+}
+
+// routesCmd prints the resolved route table
+var routesCmd = &cobra.Command{
+	Use:     "routes",
+	Short:   "List the resolved route table",
+	Long:    "Prints method, path, controller, handler, middleware chain and source position of every route mkrt would generate",
+	Example: "  god routes\n  god routes --prefix /api/user --method get,post\n  god routes --json",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		apiRoot, _ := cmd.Flags().GetString("api-root")
+		prefix, _ := cmd.Flags().GetString("prefix")
+		method, _ := cmd.Flags().GetString("method")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		listroutes.ListRoutes(apiRoot, prefix, method, asJSON)
 	},
 }
 
@@ -61,10 +126,8 @@ var buildCmd = &cobra.Command{
 	Example: "  god build api user-service\n  god build admin-console --version v1.2.0\n  god build payment-service --app-root services --api-root api/v1",
 	Args:    cobra.RangeArgs(1, 2), // Accepts 1 or 2 arguments
 	Run: func(cmd *cobra.Command, args []string) {
-		content, err := templateFS.ReadFile("templates/basic/app/api/home/router.go.tmpl")
-		if err != nil {
-			service.OutputFatal(err)
-		}
+		static, _ := cmd.Flags().GetBool("static")
+		content := readRouterTemplate(static)
 		app := args[0]
 		isApi := false
 		appRoot, _ := cmd.Flags().GetString("app-root")
@@ -77,10 +140,47 @@ var buildCmd = &cobra.Command{
 			isApi = true
 		}
 
-		build.Build(string(content), app, appRoot, apiRoot, version, goos, goarch, isApi)
+		build.Build(content, app, appRoot, apiRoot, version, goos, goarch, isApi, static)
+	},
+}
+
+// devCmd runs an API app with hot reload
+var devCmd = &cobra.Command{
+	Use:     "dev [app-name]",
+	Short:   "Run an API app and reload it on changes",
+	Long:    "Regenerates the router, builds the API app into a temp dir and runs it.\nGo changes rebuild and restart the app, config changes restart it; compile errors are shown while the previous build keeps running.",
+	Example: "  god dev\n  god dev admin --port 8081\n  god dev --config ./config.dev.yaml",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		static, _ := cmd.Flags().GetBool("static")
+		content := readRouterTemplate(static)
+		app := ""
+		if len(args) > 0 {
+			app = args[0]
+		}
+		apiRoot, _ := cmd.Flags().GetString("api-root")
+		configPath, _ := cmd.Flags().GetString("config")
+		port, _ := cmd.Flags().GetString("port")
+		interval, _ := cmd.Flags().GetDuration("interval")
+
+		dev.Dev(content, app, apiRoot, configPath, port, static, interval)
 	},
 }
 
+// readRouterTemplate loads the reflection based router template, or the
+// static binding template when static is true
+func readRouterTemplate(static bool) string {
+	name := "templates/basic/app/api/home/router.go.tmpl"
+	if static {
+		name = "templates/basic/app/api/home/router_static.go.tmpl"
+	}
+	content, err := templateFS.ReadFile(name)
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	return string(content)
+}
+
 // Execute initializes and runs the CLI application
 func Execute(tmplFS embed.FS) {
 	templateFS = tmplFS
@@ -90,16 +190,70 @@ func Execute(tmplFS embed.FS) {
 	genCmd.AddCommand(actionCmd)
 	genCmd.AddCommand(middlewareCmd)
 	genCmd.AddCommand(modelCmd)
+	genCmd.AddCommand(ddlCmd)
+
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateDiffCmd)
+	migrateCmd.AddCommand(migrateNewCmd)
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
 
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(makeRouterCmd)
+	rootCmd.AddCommand(openapiCmd)
+	rootCmd.AddCommand(routesCmd)
+	rootCmd.AddCommand(lintCmd)
 	rootCmd.AddCommand(buildCmd)
+	rootCmd.AddCommand(devCmd)
 
 	// Configure persistent flags for relevant commands
-	for _, cmd := range []*cobra.Command{ctrlCmd, actionCmd, makeRouterCmd, buildCmd} {
+	for _, cmd := range []*cobra.Command{ctrlCmd, actionCmd, makeRouterCmd, buildCmd, openapiCmd, routesCmd, lintCmd, devCmd} {
 		cmd.Flags().StringP("api-root", "a", "", "API root path (e.g., 'api/v1')")
 	}
-	modelCmd.Flags().StringP("sql-path", "s", "", "Path to SQL file containing table definitions")
+	for _, cmd := range []*cobra.Command{makeRouterCmd, buildCmd, devCmd} {
+		cmd.Flags().Bool("static", false, "Generate explicit, reflection-free route bindings")
+	}
+	for _, cmd := range []*cobra.Command{makeRouterCmd, lintCmd} {
+		cmd.Flags().Bool("strict", false, "Treat warnings as errors (non-zero exit)")
+	}
+	makeRouterCmd.Flags().Bool("openapi", false, "Also write openapi.yaml next to router.go")
+	makeRouterCmd.Flags().BoolP("watch", "w", false, "Regenerate the router whenever controllers change")
+	for _, cmd := range []*cobra.Command{makeRouterCmd, devCmd} {
+		cmd.Flags().Duration("interval", time.Second, "Polling interval for file changes")
+	}
+	openapiCmd.Flags().StringP("format", "f", "yaml", "Output format: yaml or json")
+	openapiCmd.Flags().String("output", "", "Output file (default: <api-root>/openapi.<format>)")
+	routesCmd.Flags().StringP("prefix", "p", "", "Only list routes whose path starts with this prefix")
+	routesCmd.Flags().StringP("method", "m", "", "Only list these HTTP methods (comma-separated)")
+	routesCmd.Flags().Bool("json", false, "Print routes as JSON")
+	initCmd.Flags().String("db", service.DialectMySQL, "Database of the lib/db connector: mysql, postgres or sqlite")
+	modelCmd.Flags().StringP("sql-path", "s", "", "Path to SQL file containing table definitions, or a directory of migrations")
+	modelCmd.Flags().String("dsn", "", "Read the tables of a live database instead: user:pass@tcp(host:port)/db, mysql://... or a SQLite file")
+	modelCmd.Flags().StringSlice("tables", nil, "Comma-separated globs of tables to generate; prefix a glob with ! to skip matching tables")
+	modelCmd.Flags().StringP("dialect", "d", "", "SQL dialect: mysql, postgres or sqlite (default: db_dialect in gopackage.json)")
+	modelCmd.Flags().Bool("comment-tag", false, "Also write column comments as gorm comment tags")
+	modelCmd.Flags().String("nullable", service.NullableZero, "Go types of nullable columns: zero, pointer (*int64) or sqlnull (sql.NullInt64)")
+	modelCmd.Flags().Bool("force", false, "Also overwrite record.go and list.go, which are otherwise only created once")
+	modelCmd.Flags().Bool("diff", false, "Print the changes to model files as a diff without writing them")
+	ddlCmd.Flags().StringP("dialect", "d", "", "SQL dialect: mysql, postgres or sqlite (default: db_dialect in gopackage.json)")
+	ddlCmd.Flags().StringP("output", "o", "", "File to write the DDL to (default: stdout)")
+	ddlCmd.Flags().StringSlice("tables", nil, "Comma-separated globs of tables to write; prefix a glob with ! to skip matching tables")
+	migrateDiffCmd.Flags().String("from", "", "Current schema: SQL file, migrations directory or DSN (default: the models)")
+	migrateDiffCmd.Flags().String("to", "", "Desired schema: SQL file, migrations directory or DSN (default: the models)")
+	migrateDiffCmd.Flags().String("dir", "", "Directory to write the migration files to (default: <project>/migrations)")
+	migrateDiffCmd.Flags().StringP("dialect", "d", "", "SQL dialect: mysql, postgres or sqlite (default: db_dialect in gopackage.json)")
+	migrateDiffCmd.Flags().StringSlice("tables", nil, "Comma-separated globs of tables to compare; prefix a glob with ! to skip matching tables")
+	migrateDiffCmd.Flags().Bool("dry-run", false, "Print the up and down migrations instead of writing them")
+	migrateNewCmd.Flags().String("dir", "", "Directory of the migration files (default: <project>/migrations)")
+	for _, cmd := range []*cobra.Command{migrateUpCmd, migrateDownCmd, migrateStatusCmd} {
+		cmd.Flags().StringP("config", "c", "", "Config file with the database connection (default: ./config.yaml in the project)")
+		cmd.Flags().String("dir", "", "Directory of the migration files (default: <project>/migrations)")
+	}
+	migrateUpCmd.Flags().IntP("steps", "n", 0, "Apply at most this many migrations (default: all)")
+	migrateDownCmd.Flags().IntP("steps", "n", 1, "Number of migrations to revert")
+	devCmd.Flags().StringP("config", "c", "", "Config file passed to the app as --config")
+	devCmd.Flags().StringP("port", "p", "", "Port passed to the app as --port")
 	buildCmd.Flags().StringP("app-root", "r", "", "App root path (e.g., 'app')")
 	buildCmd.Flags().StringP("version", "v", "", "App version (e.g., 'v1.0.0')")
 	buildCmd.Flags().StringP("goos", "o", "", "GOOS (e.g., 'linux')")
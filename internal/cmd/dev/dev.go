@@ -0,0 +1,228 @@
+// Package dev runs an API app under a hot-reloading supervisor: the router
+// is regenerated, the app rebuilt and restarted whenever sources change.
+package dev
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/jiajia556/god/internal/cmd/makerouter"
+	"github.com/jiajia556/god/internal/service"
+)
+
+// stopTimeout is how long the app gets to shut down before it is killed
+const stopTimeout = 5 * time.Second
+
+// configExts lists extensions of files that restart the app without a rebuild
+var configExts = []string{".yaml", ".yml", ".json", ".toml", ".env"}
+
+// server supervises one running build of the app
+type server struct {
+	app        string
+	buildPath  string // Package directory passed to go build
+	tmpDir     string // Directory holding the compiled binaries
+	args       []string
+	generation int
+	binary     string // Binary of the running process
+	proc       *process
+}
+
+// process is a started build of the app
+type process struct {
+	cmd      *exec.Cmd
+	exited   chan struct{} // Closed once the process has exited
+	stopping atomic.Bool   // Set when the supervisor stops the process on purpose
+}
+
+// Dev regenerates the router of the API app, builds it into a temporary
+// directory and runs it, passing --config and --port through when set.
+// Go sources and config files under the project root are polled every
+// interval: Go changes rebuild and restart the app, config changes only
+// restart it. When compilation fails the errors are printed and the
+// previous healthy process keeps serving.
+func Dev(routerTmpl, app, apiRoot, configPath, port string, static bool, interval time.Duration) {
+	if apiRoot == "" {
+		defaultRoot, err := service.GetDefaultApiRoot()
+		if err != nil {
+			service.OutputFatal(err)
+		}
+		apiRoot = defaultRoot
+		if app != "" {
+			apiRoot = filepath.Join(filepath.Dir(defaultRoot), app)
+		}
+	}
+	if app == "" {
+		app = filepath.Base(apiRoot)
+	}
+	// Absolute paths keep router.go comparable with scanned file paths
+	apiRoot, err := filepath.Abs(apiRoot)
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	if !service.FileExists(apiRoot) {
+		service.OutputFatal(fmt.Sprintf("API app %s not found at %s", app, apiRoot))
+	}
+
+	projectRoot, err := service.GetProjectRoot()
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	rg, err := makerouter.NewRegenerator(routerTmpl, apiRoot, static, false)
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	tmpDir, err := os.MkdirTemp("", "god-dev-*")
+	if err != nil {
+		service.OutputFatal(err)
+	}
+
+	srv := &server{app: app, buildPath: apiRoot, tmpDir: tmpDir}
+	if configPath != "" {
+		srv.args = append(srv.args, "--config", configPath)
+	}
+	if port != "" {
+		srv.args = append(srv.args, "--port", port)
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	routerPath := rg.RouterPath()
+	watched := func(path string) bool {
+		return path != routerPath && isWatchedFile(path)
+	}
+	snapshot := service.ScanFiles([]string{projectRoot}, watched)
+	srv.reload(rg)
+	service.OutputInfof("Watching %s for changes (Ctrl+C to stop)", projectRoot)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-interrupt:
+			srv.stop()
+			_ = os.RemoveAll(tmpDir)
+			return
+		case <-ticker.C:
+		}
+
+		next := service.ScanFiles([]string{projectRoot}, watched)
+		changed := service.ChangedFiles(snapshot, next)
+		snapshot = next
+		if len(changed) == 0 {
+			continue
+		}
+		service.OutputInfof("Changed: %s", strings.Join(changed, ", "))
+		if hasGoFile(changed) {
+			srv.reload(rg)
+		} else if srv.binary != "" {
+			srv.restart(srv.binary)
+		}
+	}
+}
+
+// reload regenerates the router, rebuilds the app and restarts it. Failing
+// steps are reported and leave the running process untouched.
+func (s *server) reload(rg *makerouter.Regenerator) {
+	if _, err := rg.Run(); err != nil {
+		service.OutputErrorf("%v", err)
+		return
+	}
+
+	s.generation++
+	binary := filepath.Join(s.tmpDir, fmt.Sprintf("%s-%d", s.app, s.generation))
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	service.OutputInfof("Building %s...", s.app)
+	if out, err := service.RunCommandOutput("go", "build", "-o", binary, s.buildPath); err != nil {
+		service.OutputErrorf("Build failed: %v\n%s", err, strings.TrimRight(out, "\n"))
+		if s.proc != nil {
+			service.OutputInfof("Keeping the previous build running")
+		}
+		return
+	}
+
+	previous := s.binary
+	s.restart(binary)
+	if previous != "" && previous != binary {
+		_ = os.Remove(previous)
+	}
+}
+
+// restart stops the running process, if any, and starts binary
+func (s *server) restart(binary string) {
+	s.stop()
+
+	cmd := exec.Command(binary, s.args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		service.OutputErrorf("Failed to start %s: %v", s.app, err)
+		return
+	}
+	service.OutputInfof("Started %s (pid %d)", s.app, cmd.Process.Pid)
+
+	p := &process{cmd: cmd, exited: make(chan struct{})}
+	go func() {
+		if err := cmd.Wait(); err != nil && !p.stopping.Load() {
+			service.OutputErrorf("%s exited: %v", s.app, err)
+		}
+		close(p.exited)
+	}()
+	s.binary, s.proc = binary, p
+}
+
+// stop interrupts the running process and kills it when it does not exit
+// within stopTimeout
+func (s *server) stop() {
+	p := s.proc
+	if p == nil {
+		return
+	}
+	s.proc = nil
+
+	p.stopping.Store(true)
+	select {
+	case <-p.exited:
+		return
+	default:
+	}
+	if runtime.GOOS == "windows" || p.cmd.Process.Signal(os.Interrupt) != nil {
+		_ = p.cmd.Process.Kill()
+	}
+	select {
+	case <-p.exited:
+	case <-time.After(stopTimeout):
+		_ = p.cmd.Process.Kill()
+		<-p.exited
+	}
+}
+
+// isWatchedFile reports whether path is a Go source or config file whose
+// change should reload the app
+func isWatchedFile(path string) bool {
+	if strings.HasSuffix(path, "_test.go") {
+		return false
+	}
+	ext := filepath.Ext(path)
+	return ext == ".go" || service.InArray(configExts, ext)
+}
+
+func hasGoFile(paths []string) bool {
+	for _, p := range paths {
+		if strings.HasSuffix(p, ".go") {
+			return true
+		}
+	}
+	return false
+}
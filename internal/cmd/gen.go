@@ -4,6 +4,7 @@ import (
 	"github.com/jiajia556/god/internal/cmd/addaction"
 	"github.com/jiajia556/god/internal/cmd/addcontroller"
 	"github.com/jiajia556/god/internal/cmd/addmiddleware"
+	"github.com/jiajia556/god/internal/cmd/makeddl"
 	"github.com/jiajia556/god/internal/cmd/makemodel"
 	"github.com/jiajia556/god/internal/service"
 	"github.com/spf13/cobra"
@@ -45,7 +46,7 @@ var actionCmd = &cobra.Command{
 	Use:     "act [controller-route] [actions...]",
 	Short:   "Add actions to an existing controller",
 	Long:    "Adds one or more action methods to a specified controller",
-	Example: "  god gen act user getInfo\n  god gen act product search filter",
+	Example: "  god gen act user getInfo\n  god gen act product search filter\n  god gen act user update:put remove:delete show:get,head",
 	Args:    cobra.MinimumNArgs(2), // Requires at least controller route and one action
 	Run: func(cmd *cobra.Command, args []string) {
 		apiRoot, _ := cmd.Flags().GetString("api-root")
@@ -74,8 +75,8 @@ var middlewareCmd = &cobra.Command{
 var modelCmd = &cobra.Command{
 	Use:     "model",
 	Short:   "Generate database model files",
-	Long:    "Generate Go model files from SQL schema definitions.\nCreates record and list type files based on SQL CREATE TABLE statements.",
-	Example: "  god gen model --sql-path schema.sql\n  god gen model -s ./database/schema.sql",
+	Long:    "Generate Go model files from SQL schema definitions, from a directory of migrations replayed in filename order,\nor from the tables of a live MySQL or SQLite database (read with the mysql or sqlite3 client).\nWrites the struct of each CREATE TABLE statement to model/<name>/<name>_gen.go on every run,\nand creates the record and list files, which are yours to edit, when missing.",
+	Example: "  god gen model --sql-path schema.sql\n  god gen model -s ./database/schema.sql\n  god gen model -s schema.pg.sql --dialect postgres\n  god gen model -s schema.sqlite.sql --dialect sqlite\n  god gen model -s schema.sql --diff\n  god gen model -s ./migrations\n  god gen model --dsn \"user:pass@tcp(127.0.0.1:3306)/shop\" --tables \"order*,!*_bak\"\n  god gen model --dsn ./data/app.db",
 	Run: func(cmd *cobra.Command, args []string) {
 		recordContent, err := templateFS.ReadFile("templates/basic/model/record.go.tmpl")
 		if err != nil {
@@ -85,7 +86,36 @@ var modelCmd = &cobra.Command{
 		if err != nil {
 			service.OutputFatal(err)
 		}
+		genContent, err := templateFS.ReadFile("templates/basic/model/gen.go.tmpl")
+		if err != nil {
+			service.OutputFatal(err)
+		}
 		sqlPath, _ := cmd.Flags().GetString("sql-path")
-		makemodel.MakeModel(sqlPath, string(recordContent), string(listContent))
+		dsn, _ := cmd.Flags().GetString("dsn")
+		tables, _ := cmd.Flags().GetStringSlice("tables")
+		dialect, _ := cmd.Flags().GetString("dialect")
+		commentTags, _ := cmd.Flags().GetBool("comment-tag")
+		nullable, _ := cmd.Flags().GetString("nullable")
+		force, _ := cmd.Flags().GetBool("force")
+		diff, _ := cmd.Flags().GetBool("diff")
+		opts := service.ModelOptions{Dialect: dialect, CommentTags: commentTags, Nullable: nullable}
+		tmpls := makemodel.Templates{Record: string(recordContent), List: string(listContent), Gen: string(genContent)}
+		src := makemodel.Source{SQLPath: sqlPath, DSN: dsn, Tables: tables}
+		makemodel.MakeModel(src, opts, makemodel.WriteOptions{Force: force, Diff: diff}, tmpls)
+	},
+}
+
+// ddlCmd writes the DDL of the model structs
+var ddlCmd = &cobra.Command{
+	Use:     "ddl",
+	Short:   "Generate SQL DDL from model structs",
+	Long:    "Parses the model packages under model/ and writes CREATE TABLE statements for their gorm tags,\nthe reverse of god gen model. Prints to stdout unless --output is given.",
+	Example: "  god gen ddl\n  god gen ddl --dialect postgres --output schema.sql\n  god gen ddl --tables \"user*\"",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dialect, _ := cmd.Flags().GetString("dialect")
+		output, _ := cmd.Flags().GetString("output")
+		tables, _ := cmd.Flags().GetStringSlice("tables")
+		makeddl.MakeDDL(dialect, output, tables)
 	},
 }
@@ -11,7 +11,9 @@ import (
 	"strings"
 )
 
-func InitProject(name string, tmplFS embed.FS) {
+// InitProject scaffolds project name from the embedded templates, with the
+// lib/db connector of the given SQL dialect
+func InitProject(name, dialect string, tmplFS embed.FS) {
 	defer func() {
 		service.CmdDir = "./" + name
 		service.RunCommand("go", "mod", "tidy")
@@ -25,15 +27,25 @@ func InitProject(name string, tmplFS embed.FS) {
 			return nil
 		}
 
-		path := name + strings.TrimPrefix(originalPath, "templates/basic")
+		relPath := strings.TrimPrefix(originalPath, "templates/basic")
+		// only the connector of the selected dialect is generated, next to
+		// the dialect-neutral migration runner
+		if strings.HasPrefix(relPath, "/lib/db/") && !strings.HasPrefix(relPath, "/lib/db/"+dialect+"/") &&
+			!strings.HasPrefix(relPath, "/lib/db/migrate/") {
+			return nil
+		}
+
+		path := name + relPath
 		dirPath := filepath.Dir(path)
 		os.MkdirAll(dirPath, 0755)
 		tmplName := filepath.Base(originalPath)
 		if strings.HasSuffix(tmplName, "router.go.tmpl") ||
+			strings.HasSuffix(tmplName, "router_static.go.tmpl") ||
 			strings.HasSuffix(tmplName, "controller.tmpl") ||
 			strings.HasSuffix(tmplName, "middleware.tmpl") ||
 			strings.HasSuffix(tmplName, "record.go.tmpl") ||
-			strings.HasSuffix(tmplName, "list.go.tmpl") {
+			strings.HasSuffix(tmplName, "list.go.tmpl") ||
+			strings.HasSuffix(tmplName, "gen.go.tmpl") {
 			return nil
 		}
 
@@ -50,9 +62,9 @@ func InitProject(name string, tmplFS embed.FS) {
 
 		fileName := filepath.Base(targetPath)
 
-		onlyProjectNameTmpls := []string{"mysql.go", "go.mod", "main.go", "gopackage.json"}
-		if service.InArray(onlyProjectNameTmpls, fileName) {
-			data := template.OnlyProjectNameData{ProjectName: name}
+		projectTmpls := []string{dialect + ".go", "go.mod", "main.go", "gopackage.json"}
+		if service.InArray(projectTmpls, fileName) {
+			data := template.ProjectData{ProjectName: name, DBDialect: dialect}
 			err = template.CreateFile(content, data, targetPath)
 			if err != nil {
 				service.OutputFatal(err)
@@ -0,0 +1,34 @@
+// Package lint checks controller annotations and routes without generating code.
+package lint
+
+import (
+	"fmt"
+
+	"github.com/jiajia556/god/internal/cmd/makerouter"
+	"github.com/jiajia556/god/internal/service"
+)
+
+// Lint prints every diagnostic found under apiRoot and exits non-zero on
+// errors, or on any diagnostic when strict is set
+func Lint(apiRoot string, strict bool) {
+	routes, diagnostics, err := makerouter.Lint(apiRoot)
+	if err != nil {
+		service.OutputFatal(err)
+	}
+
+	errors, warnings := 0, 0
+	for _, d := range diagnostics {
+		service.OutputErrorf("%s", d)
+		if d.Severity == makerouter.SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+
+	summary := fmt.Sprintf("%d routes, %d errors, %d warnings", len(routes), errors, warnings)
+	if errors > 0 || strict && warnings > 0 {
+		service.OutputFatal(summary)
+	}
+	service.OutputInfof("%s", summary)
+}
@@ -0,0 +1,101 @@
+// Package listroutes prints the route table resolved by makerouter.
+package listroutes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jiajia556/god/internal/cmd/makerouter"
+	"github.com/jiajia556/god/internal/service"
+)
+
+// routeRow is a single route/method pair as printed by `god routes`
+type routeRow struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Package     string   `json:"package"`
+	Controller  string   `json:"controller"`
+	Handler     string   `json:"handler"`
+	Middlewares []string `json:"middlewares"`
+	File        string   `json:"file"`
+	Line        int      `json:"line"`
+}
+
+// ListRoutes prints the routes under apiRoot, optionally filtered by path
+// prefix and a comma-separated list of HTTP methods
+func ListRoutes(apiRoot, prefix, methods string, asJSON bool) {
+	routes, err := makerouter.Analyze(apiRoot)
+	if err != nil {
+		service.OutputFatal(err)
+	}
+
+	var methodFilter []string
+	if methods != "" {
+		if methodFilter, err = service.ParseHTTPMethods(methods); err != nil {
+			service.OutputFatal(err)
+		}
+	}
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+
+	projectRoot, _ := service.GetProjectRoot()
+	rows := make([]routeRow, 0, len(routes))
+	for _, r := range routes {
+		path := "/" + r.Path
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		for _, m := range r.HTTPMethods {
+			if len(methodFilter) > 0 && !service.InArray(methodFilter, m) {
+				continue
+			}
+			rows = append(rows, routeRow{
+				Method:      m,
+				Path:        path,
+				Package:     r.PkgPath,
+				Controller:  r.Controller,
+				Handler:     r.Handler,
+				Middlewares: append(append([]string{}, r.GroupMiddlewares...), r.Middlewares...),
+				File:        relativePath(projectRoot, r.File),
+				Line:        r.Line,
+			})
+		}
+	}
+
+	if asJSON {
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			service.OutputFatal(err)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tCONTROLLER\tHANDLER\tMIDDLEWARE\tSOURCE")
+	for _, row := range rows {
+		middlewares := "-"
+		if len(row.Middlewares) > 0 {
+			middlewares = strings.Join(row.Middlewares, " -> ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s:%d\n", row.Method, row.Path,
+			filepath.Base(row.Package)+"."+row.Controller, row.Handler, middlewares, row.File, row.Line)
+	}
+	_ = w.Flush()
+}
+
+// relativePath shortens file to a path relative to root when possible
+func relativePath(root, file string) string {
+	if root == "" {
+		return file
+	}
+	if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return file
+}
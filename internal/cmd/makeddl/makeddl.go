@@ -0,0 +1,57 @@
+package makeddl
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/jiajia556/god/internal/service"
+	"github.com/jiajia556/god/internal/template"
+)
+
+// MakeDDL writes CREATE TABLE statements for the model structs under the
+// project's model directory, read from their gorm tags.
+// Parameters:
+//   - dialect:  SQL dialect; empty defaults to the project's db_dialect
+//   - output:   File to write; empty prints the DDL to stdout
+//   - tables:   Globs of tables to write, !glob to skip tables
+func MakeDDL(dialect, output string, tables []string) {
+	var err error
+	if dialect == "" {
+		dialect, err = service.GetDBDialect()
+	} else {
+		dialect, err = service.ParseDialect(dialect)
+	}
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	projectRoot, err := service.GetProjectRoot()
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	modelDir := filepath.Join(projectRoot, "model")
+	if !service.FileExists(modelDir) {
+		service.OutputFatal("model directory not found at " + modelDir)
+	}
+
+	schema, err := service.ParseModels(modelDir, dialect)
+	if err != nil {
+		service.OutputFatal("Error reading models: ", err.Error())
+	}
+	if err := schema.FilterTables(tables); err != nil {
+		service.OutputFatal(err)
+	}
+	if len(schema.Tables) == 0 {
+		service.OutputFatal("No models with gorm tags found under " + modelDir)
+	}
+
+	ddl := fmt.Sprintf("-- Generated by god gen ddl (%s) from the models under model/\n\n", dialect) +
+		service.WriteDDL(schema, dialect)
+	if output == "" {
+		fmt.Print(ddl)
+		return
+	}
+	if err := template.WriteFileAtomic(output, []byte(ddl)); err != nil {
+		service.OutputFatal(err)
+	}
+	service.OutputInfof("Generated %s (%d tables)", output, len(schema.Tables))
+}
@@ -1,93 +1,369 @@
 package makemodel
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/jiajia556/god/internal/service"
 	"github.com/jiajia556/god/internal/template"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-// MakeModel generates model files from SQL CREATE TABLE statements
+// Templates holds the contents of the model templates
+type Templates struct {
+	Record string // record.go, created once and then owned by the user
+	List   string // list.go, created once and then owned by the user
+	Gen    string // <model>_gen.go, rewritten on every run
+}
+
+// WriteOptions controls how model files are written
+type WriteOptions struct {
+	Force bool // Also overwrite the user-owned record.go and list.go
+	Diff  bool // Print the changes as unified diffs instead of writing files
+}
+
+// Source selects where the table definitions are read from
+type Source struct {
+	SQLPath string   // SQL file, or directory of migrations
+	DSN     string   // Live database, read instead of SQLPath
+	Tables  []string // Globs of tables to generate, !glob to skip tables
+}
+
+// MakeModel generates model files from SQL CREATE TABLE statements, or from
+// the tables of a live database. A SQL file may contain any other
+// statements, which are ignored. The struct and column types of each table
+// are rewritten to model/<name>/<name>_gen.go on every run, while record.go
+// and list.go are only created when missing.
 // Parameters:
-//   - sqlFilePath:  Path to SQL file containing table definitions
-//   - recordTmpl:   Content of template for record generation
-//   - listTmpl:     Content of template for list type generation
-func MakeModel(sqlFilePath, recordTmpl, listTmpl string) {
-	defer runPostGenerationTasks()
+//   - src:    SQL path or DSN, and the tables to generate
+//   - opts:   Generation options; an empty Dialect defaults to the DSN's or the project's db_dialect
+//   - write:  --force and --diff settings
+//   - tmpls:  Contents of the model templates
+func MakeModel(src Source, opts service.ModelOptions, write WriteOptions, tmpls Templates) {
+	switch {
+	case src.SQLPath == "" && src.DSN == "":
+		service.OutputFatal("Either --sql-path or --dsn is required")
+	case src.SQLPath != "" && src.DSN != "":
+		service.OutputFatal("--sql-path and --dsn cannot be used together")
+	}
 
-	if sqlFilePath == "" {
-		service.OutputFatal("SQL file path is required")
+	var err error
+	if opts.Dialect == "" && src.DSN != "" {
+		opts.Dialect = service.DSNDialect(src.DSN)
 	}
+	if opts.Dialect == "" {
+		opts.Dialect, err = service.GetDBDialect()
+	} else {
+		opts.Dialect, err = service.ParseDialect(opts.Dialect)
+	}
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	dialect := opts.Dialect
+	if d := service.DSNDialect(src.DSN); d != "" && d != dialect {
+		service.OutputFatal(fmt.Sprintf("--dsn points to a %s database but the dialect is %s", d, dialect))
+	}
+	if opts.TypeMapping, err = service.GetTypeMapping(); err != nil {
+		service.OutputFatal(err)
+	}
+	if opts.Nullable == "" {
+		opts.Nullable = service.NullableZero
+	}
+	if !service.InArray(service.NullableModes, opts.Nullable) {
+		service.OutputFatal(fmt.Sprintf("unknown --nullable mode %q, expected one of: %s",
+			opts.Nullable, strings.Join(service.NullableModes, ", ")))
+	}
+	checkDBPackage(dialect)
 
-	sqls, err := service.ExtractCreateTables(sqlFilePath)
+	var schema *service.Schema
+	if src.DSN != "" {
+		if schema, err = service.NewIntrospector().Introspect(src.DSN, dialect); err != nil {
+			service.OutputFatal("Error reading database schema: ", err.Error())
+		}
+	} else if schema, err = service.ParseDDLFile(src.SQLPath, dialect); err != nil {
+		service.OutputFatal("Error parsing SQL: ", err.Error())
+	}
+	if err := schema.FilterTables(src.Tables); err != nil {
+		service.OutputFatal(err)
+	}
+	if len(schema.Tables) == 0 {
+		service.OutputInfof("No tables found")
+	}
+	projectName, err := service.GetProjectName()
 	if err != nil {
-		service.OutputFatal("Error extracting SQL statements: ", err.Error())
+		service.OutputFatal(fmt.Sprintf("Error getting project name: %v", err))
 	}
 
-	for _, sql := range sqls {
-		GenerateModelFromSQL(sql, recordTmpl, listTmpl)
+	changed := false
+	for _, model := range service.GenerateModels(schema, opts) {
+		if GenerateModelFromStruct(model, projectName, dialect, write, tmpls) {
+			changed = true
+		}
+	}
+	switch {
+	case write.Diff && !changed:
+		service.OutputInfof("Models are up to date")
+	case changed && !write.Diff:
+		runPostGenerationTasks()
 	}
 }
 
-// GenerateModelFromSQL creates model files for a single SQL CREATE TABLE statement
-func GenerateModelFromSQL(sql, recordTmpl, listTmpl string) {
-	// Generate model structure from SQL
-	structText, structName, err := service.GenerateModelStruct(sql)
+// checkDBPackage warns when the project has no lib/db connector for dialect,
+// which the generated models import
+func checkDBPackage(dialect string) {
+	projectRoot, err := service.GetProjectRoot()
 	if err != nil {
-		service.OutputFatal(fmt.Sprintf("Error generating model struct: %v", err))
-		return
+		service.OutputFatal(err)
 	}
+	if _, err := os.Stat(filepath.Join(projectRoot, "lib", "db", dialect)); err != nil {
+		service.OutputErrorf("Warning: lib/db/%s not found; generated models import it (create the project with `god init --db %s`)",
+			dialect, dialect)
+	}
+}
 
-	// Prepare model package name
-	modelPkg := strings.ToLower(structName)
+// GenerateModelFromStruct writes the files of a single model and reports
+// whether any of them changed
+func GenerateModelFromStruct(model service.ModelStruct, projectName, dialect string, write WriteOptions, tmpls Templates) bool {
+	modelPkg := strings.ToLower(model.Name)
+	dir := filepath.Join("model", modelPkg)
+	declared := declaredNames(dir)
+	data := modelData(model, modelPkg, projectName, dialect, declared)
 
-	// Generate record file
-	generateModelFile(modelPkg, structName, structText, recordTmpl, "record.go")
+	changed := false
+	for _, f := range []struct{ name, tmpl string }{{"record.go", tmpls.Record}, {"list.go", tmpls.List}} {
+		path := filepath.Join(dir, f.name)
+		if service.FileExists(path) && !write.Force {
+			continue
+		}
+		changed = writeModelFile(f.tmpl, data, path, write.Diff) || changed
+	}
 
-	// Generate list file
-	generateModelFile(modelPkg, structName, structText, listTmpl, "list.go")
+	// record.go of models generated before *_gen.go existed declares the struct
+	recordPath := filepath.Join(dir, "record.go")
+	if !write.Force && declaresType(recordPath, model.Name) {
+		service.OutputErrorf("Warning: %s declares %s; move your changes out of the struct and delete it, "+
+			"or rerun with --force, so that %s_gen.go can own it", recordPath, model.Name, modelPkg)
+		return changed
+	}
+	return writeModelFile(tmpls.Gen, data, filepath.Join(dir, modelPkg+"_gen.go"), write.Diff) || changed
 }
 
-// runPostGenerationTasks executes post-processing commands
-func runPostGenerationTasks() {
-	service.RunCommand("goimports", "-w", ".")
-	service.RunCommand("go", "mod", "tidy")
+// modelData prepares the template data of a model. Generated declarations
+// whose names the user-owned files of the model declare are left out.
+func modelData(model service.ModelStruct, modelPkg, projectName, dialect string, declared map[string]bool) template.ModelData {
+	data := template.ModelData{
+		ModelPkg:        modelPkg,
+		ProjectName:     projectName,
+		DBPkg:           dialect,
+		ModelStruct:     model.Source,
+		ModelStructName: model.Name,
+		Imports:         append([]string{}, model.Imports...),
+		PKField:         model.PKField,
+		PKType:          model.PKType,
+		PKExists:        model.PKExists,
+		Declared:        declared,
+	}
+	for _, path := range model.ProjectImports {
+		data.Imports = append(data.Imports, projectName+"/"+path)
+	}
+
+	for _, e := range model.Enums {
+		enum := template.EnumType{
+			Name:      e.Name,
+			Column:    e.Column,
+			Set:       e.Set,
+			ConstList: strings.Join(e.Consts, ", "),
+		}
+		for i, v := range e.Values {
+			enum.Values = append(enum.Values, template.EnumValue{Const: e.Consts[i], Literal: strconv.Quote(v)})
+		}
+		if e.Set && !service.InArray(data.Imports, "strings") {
+			data.Imports = append(data.Imports, "strings")
+		}
+		data.Enums = append(data.Enums, enum)
+	}
+	if len(data.Enums) > 0 {
+		data.Imports = append(data.Imports, "database/sql/driver", "fmt")
+	}
+
+	for _, c := range model.Columns {
+		data.Columns = append(data.Columns, template.ModelColumn{
+			Const:      "Column" + c.Field,
+			Name:       c.Name,
+			Field:      c.Field,
+			Type:       c.Type,
+			Comparable: c.Comparable,
+		})
+	}
+	for _, f := range model.Finders {
+		var params, conds []string
+		for _, c := range f.Columns {
+			param := paramName(c.Field)
+			params = append(params, param+" "+c.Type)
+			conds = append(conds, "Column"+c.Field+": "+param)
+		}
+		data.Finders = append(data.Finders, template.ModelFinder{
+			Name:   f.Name,
+			Unique: f.Unique,
+			Params: strings.Join(params, ", "),
+			Conds:  strings.Join(conds, ", "),
+		})
+	}
+	if !declared["Query"] {
+		data.Imports = append(data.Imports, "gorm.io/gorm", "gorm.io/gorm/clause")
+	}
+
+	var skipped []string
+	for name := range declared {
+		if generatesName(data, name) {
+			skipped = append(skipped, name)
+		}
+	}
+	if len(skipped) > 0 {
+		sort.Strings(skipped)
+		service.OutputErrorf("Warning: model/%s declares %s; the generated declarations are left out",
+			modelPkg, strings.Join(skipped, ", "))
+	}
+	return data
 }
 
-// generateModelFile handles file creation logic for model components
-func generateModelFile(modelPkg, structName, structText, templatePath, fileName string) {
-	// Set up file paths
-	filePath := filepath.Join("model", modelPkg, fileName)
+// generatesName reports whether the generated file of a model declares
+// name, a top-level name or Type.Method
+func generatesName(data template.ModelData, name string) bool {
+	if name == "Query" || name == "List.Query" {
+		return true
+	}
+	for _, f := range data.Finders {
+		if name == "Record."+f.Name && f.Unique || name == "List."+f.Name && !f.Unique {
+			return true
+		}
+	}
+	for _, c := range data.Columns {
+		if name == c.Const {
+			return true
+		}
+		if !c.Comparable || data.Declared["Query"] {
+			continue
+		}
+		for _, method := range []string{"Where", "OrderBy"} {
+			if m := strings.TrimPrefix(name, "Query."+method+c.Field); m != name && (m == "" || m == "In" || m == "Desc") {
+				return true
+			}
+		}
+	}
+	return false
+}
 
-	// Skip if file already exists
-	if service.FileExists(filePath) {
-		return
+// paramName turns a field name into a parameter name that shadows neither
+// a keyword, a predeclared identifier nor the method receiver
+func paramName(field string) string {
+	name := strings.ToLower(field[:1]) + field[1:]
+	if token.IsKeyword(name) || types.Universe.Lookup(name) != nil || service.InArray([]string{"r", "l", "q"}, name) {
+		name += "Value"
 	}
+	return name
+}
 
-	// Prepare template data
-	projectName, err := service.GetProjectName()
+// declaredNames returns the top-level names and the Type.Method names
+// declared by the Go files of dir that god does not regenerate
+func declaredNames(dir string) map[string]bool {
+	declared := make(map[string]bool)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, path := range files {
+		if strings.HasSuffix(path, "_gen.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					declared[d.Name.Name] = true
+					continue
+				}
+				recv := d.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*ast.Ident); ok {
+					declared[ident.Name+"."+d.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						declared[s.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, name := range s.Names {
+							declared[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return declared
+}
+
+// writeModelFile renders and gofmts a model file, then writes it when its
+// content changed, or prints the change in diff mode. It reports whether
+// the file changed.
+func writeModelFile(tmpl string, data template.ModelData, path string, diff bool) bool {
+	content, err := template.Render(tmpl, data, filepath.Base(path))
 	if err != nil {
-		service.OutputFatal(fmt.Sprintf("Error getting project name: %v", err))
+		service.OutputFatal(fmt.Sprintf("Error creating %s: %v", path, err))
+	}
+	if content, err = format.Source(content); err != nil {
+		service.OutputFatal(fmt.Sprintf("Error formatting %s: %v", path, err))
 	}
 
-	data := template.ModelData{
-		ModelPkg:        modelPkg,
-		ProjectName:     projectName,
-		ModelStruct:     structText,
-		ModelStructName: structName,
+	existing, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(existing, content) {
+		return false
+	}
+	if diff {
+		from := path
+		if err != nil {
+			from = "/dev/null"
+		}
+		fmt.Print(service.UnifiedDiff(from, path, existing, content))
+		return true
 	}
 
-	// Create directory structure
-	dir := filepath.Dir(filePath)
+	dir := filepath.Dir(path)
 	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
 		service.OutputFatal(fmt.Sprintf("Error creating directory %s: %v", dir, err))
-		return
 	}
+	if err = template.WriteFileAtomic(path, content); err != nil {
+		service.OutputFatal(fmt.Sprintf("Error creating %s: %v", path, err))
+	}
+	service.OutputInfof("Generated %s", path)
+	return true
+}
 
-	// Generate file from template
-	if err = template.CreateFile(templatePath, data, filePath); err != nil {
-		service.OutputFatal(fmt.Sprintf("Error creating %s: %v", fileName, err))
+// declaresType reports whether the Go file at path declares the type name
+func declaresType(path, name string) bool {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return false
 	}
+	return regexp.MustCompile(`(?m)^type\s+` + regexp.QuoteMeta(name) + `\s+struct\b`).Match(src)
+}
+
+// runPostGenerationTasks executes post-processing commands
+func runPostGenerationTasks() {
+	service.RunCommand("goimports", "-w", ".")
+	service.RunCommand("go", "mod", "tidy")
 }
@@ -0,0 +1,91 @@
+package makemodel
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jiajia556/god/internal/service"
+	"github.com/jiajia556/god/internal/template"
+)
+
+const testProject = "example.com/models"
+
+// testModels generates the models of a SQLite schema into a project with a
+// stub lib/db/sqlite and runs the tests of testdata/model_test.go on them.
+// The modules of a new project must be in the module cache.
+func testModels(t *testing.T, ddl string) {
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	source, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := func(path ...string) string {
+		content, err := os.ReadFile(filepath.Join(append([]string{source}, path...)...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+	tmpls := Templates{
+		Record: read("..", "..", "..", "templates", "basic", "model", "record.go.tmpl"),
+		List:   read("..", "..", "..", "templates", "basic", "model", "list.go.tmpl"),
+		Gen:    read("..", "..", "..", "templates", "basic", "model", "gen.go.tmpl"),
+	}
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	files := map[string]string{
+		"lib/db/sqlite/sqlite.go": read("testdata", "sqlite.go"),
+		"model/model_test.go":     read("testdata", "model_test.go"),
+	}
+	mod, err := template.Render(read("..", "..", "..", "templates", "basic", "go.mod.tmpl"),
+		template.ProjectData{ProjectName: testProject, DBDialect: service.DialectSQLite}, "go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["go.mod"] = string(mod)
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schema, err := service.ParseDDL("schema.sql", ddl, service.DialectSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, model := range service.GenerateModels(schema, service.ModelOptions{Dialect: service.DialectSQLite}) {
+		GenerateModelFromStruct(model, testProject, service.DialectSQLite, WriteOptions{}, tmpls)
+	}
+
+	env := append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off", "GOPROXY=off", "GOSUMDB=off")
+	tidy := exec.Command(gobin, "mod", "tidy")
+	tidy.Env = env
+	if out, err := tidy.CombinedOutput(); err != nil {
+		t.Skipf("project modules are not in the module cache: %s", strings.TrimSpace(string(out)))
+	}
+	test := exec.Command(gobin, "test", "./...")
+	test.Env = env
+	if out, err := test.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+}
+
+func TestGeneratedModels(t *testing.T) {
+	testModels(t, `
+CREATE TABLE accounts (code varchar(20) NOT NULL PRIMARY KEY, name text NOT NULL);
+CREATE TABLE audit_log (message text NOT NULL);
+CREATE TABLE flags (rate real NOT NULL PRIMARY KEY, name text NOT NULL);
+CREATE TABLE items (id integer PRIMARY KEY, name text NOT NULL, label text NOT NULL GENERATED ALWAYS AS (upper(name)) VIRTUAL);
+CREATE TABLE memberships (account_code varchar(20) NOT NULL, team integer NOT NULL, PRIMARY KEY (account_code, team));
+`)
+}
@@ -0,0 +1,70 @@
+package model_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"example.com/models/lib/db/sqlite"
+	"example.com/models/model/accounts"
+	"example.com/models/model/flags"
+	"example.com/models/model/items"
+	"example.com/models/model/memberships"
+)
+
+func open(t *testing.T, stmts ...string) {
+	t.Helper()
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range stmts {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadByStringKey(t *testing.T) {
+	open(t, "CREATE TABLE accounts (code varchar(20) NOT NULL PRIMARY KEY, name text NOT NULL)",
+		"INSERT INTO accounts VALUES ('abc-123', 'Ann'), ('x', 'Bob')")
+
+	r := accounts.NewRecord(nil).Read("abc-123")
+	if !r.Exists() || r.Data.Name != "Ann" {
+		t.Errorf("Read(%q) = %+v, want Ann", "abc-123", r.Data)
+	}
+	// the key is a value, never SQL
+	if r := accounts.NewRecord(nil).Read("code = 'x' OR 1 = 1"); r.Exists() {
+		t.Errorf("Read of a condition found %+v", r.Data)
+	}
+}
+
+func TestFindByKeyWithoutRead(t *testing.T) {
+	open(t, "CREATE TABLE flags (rate real NOT NULL PRIMARY KEY, name text NOT NULL)",
+		"CREATE TABLE memberships (account_code varchar(20) NOT NULL, team integer NOT NULL, PRIMARY KEY (account_code, team))",
+		"INSERT INTO flags VALUES (0.5, 'half')",
+		"INSERT INTO memberships VALUES ('abc-123', 1), ('abc-123', 2)")
+
+	if r := flags.NewRecord(nil).FindByRate(0.5); r.Data.Name != "half" {
+		t.Errorf("FindByRate(0.5) = %+v", r.Data)
+	}
+	if r := memberships.NewRecord(nil).FindByAccountCodeTeam("abc-123", 2); r.Data.Team != 2 {
+		t.Errorf("FindByAccountCodeTeam(abc-123, 2) = %+v", r.Data)
+	}
+}
+
+func TestGeneratedColumnIsReadOnly(t *testing.T) {
+	open(t, "CREATE TABLE items (id integer PRIMARY KEY, name text NOT NULL, label text NOT NULL GENERATED ALWAYS AS (upper(name)) VIRTUAL)")
+
+	r := items.NewRecord(nil)
+	r.Data.Name = "pen"
+	if err := r.Create(); err != nil {
+		t.Fatal(err)
+	}
+	r.Data.Name = "ink"
+	if err := r.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if r := items.NewRecord(nil).Read(r.Data.Id); r.Data.Label != "INK" {
+		t.Errorf("Read(%d) = %+v, want the label INK", r.Data.Id, r.Data)
+	}
+}
@@ -0,0 +1,34 @@
+// Package sqlite stands in for the lib/db/sqlite connector of a project,
+// opening a database chosen by the test
+package sqlite
+
+import (
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+var sqlDB *gorm.DB
+
+// Open opens the database file at path for the models
+func Open(path string) (*gorm.DB, error) {
+	var err error
+	sqlDB, err = gorm.Open(sqlite.Open(path), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+		Logger:         logger.Default.LogMode(logger.Silent),
+	})
+	return sqlDB, err
+}
+
+type TxContext struct {
+	db *gorm.DB
+}
+
+func NewTxContext() *TxContext {
+	return &TxContext{sqlDB}
+}
+
+func (m *TxContext) DB() *gorm.DB {
+	return m.db
+}
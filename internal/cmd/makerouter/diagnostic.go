@@ -0,0 +1,103 @@
+package makerouter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+	"unicode"
+
+	"github.com/jiajia556/god/internal/service"
+)
+
+// Severity classifies a diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The generated router would be wrong or fail at startup
+	SeverityWarning Severity = "warning" // Suspicious code; fatal only in strict mode
+)
+
+// Diagnostic is a problem found while analyzing controllers
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// String formats the diagnostic as file:line:column: severity: message
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", d.File, d.Line, d.Column, d.Severity, d.Message)
+}
+
+// report records a diagnostic at pos
+func (rg *routeGenerator) report(severity Severity, pos token.Position, format string, args ...any) {
+	rg.diagnostics = append(rg.diagnostics, Diagnostic{
+		File:     pos.Filename,
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// lint runs every check on the analyzed routes
+func (rg *routeGenerator) lint() {
+	rg.validateRoutes()
+	rg.checkMiddlewares()
+}
+
+// reportDiagnostics prints diagnostics to stderr and reports whether they
+// should fail the command: any error, or any warning in strict mode
+func reportDiagnostics(diagnostics []Diagnostic, strict bool) bool {
+	failed := false
+	for _, d := range diagnostics {
+		service.OutputErrorf("%s", d)
+		if d.Severity == SeverityError || strict {
+			failed = true
+		}
+	}
+	return failed
+}
+
+// annotationPositions maps each annotation of a doc comment to its position
+func annotationPositions(fset *token.FileSet, doc *ast.CommentGroup) map[string]token.Position {
+	positions := make(map[string]token.Position)
+	if doc == nil {
+		return positions
+	}
+	for _, comment := range doc.List {
+		if name, _ := splitAnnotation(comment.Text); name != "" {
+			positions[name] = fset.Position(comment.Pos())
+		}
+	}
+	return positions
+}
+
+// splitAnnotation splits a doc comment line such as "// @route /login"
+// into the annotation name and its value. name is empty when the line is
+// not an annotation.
+func splitAnnotation(comment string) (name, value string) {
+	text := strings.TrimSpace(strings.TrimPrefix(comment, "//"))
+	if !strings.HasPrefix(text, "@") {
+		return "", ""
+	}
+	end := strings.IndexFunc(text, unicode.IsSpace)
+	if end < 0 {
+		return text, ""
+	}
+	return text[:end], strings.TrimSpace(text[end:])
+}
+
+// position returns the position of the first of the given annotations
+// present on the route, or the position of the action itself
+func (r Route) position(annotations ...string) token.Position {
+	for _, a := range annotations {
+		if pos, ok := r.annotations[a]; ok {
+			return pos
+		}
+	}
+	return token.Position{Filename: r.File, Line: r.Line, Column: r.Column}
+}
@@ -0,0 +1,77 @@
+package makerouter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"strings"
+)
+
+// applyControllerAnnotations reads @group/@prefix and @middleware from the
+// doc comment of a controller type into the route template shared by its
+// actions. Any of them turns the controller into a route group; @group sets
+// the group path (leading "/" optional), replacing api/<dirs>/<controller>.
+func applyControllerAnnotations(fset *token.FileSet, doc *ast.CommentGroup, tmpl *Route) {
+	if doc == nil {
+		return
+	}
+	grouped := false
+	for _, comment := range doc.List {
+		name, value := splitAnnotation(comment.Text)
+		switch name {
+		case groupAnnotation, prefixAnnotation:
+			if value != "" {
+				tmpl.Path = strings.Trim(path.Clean("/"+value), "/")
+				tmpl.custom = true
+			}
+			grouped = true
+		case middlewareAnnotation:
+			tmpl.GroupMiddlewares = append(tmpl.GroupMiddlewares, splitMiddlewares(value)...)
+			grouped = true
+		}
+	}
+	if grouped {
+		tmpl.Group = tmpl.Path
+		tmpl.groupAnnotations = annotationPositions(fset, doc)
+	}
+}
+
+// groupRelativePath returns routePath relative to the group path, or false
+// when the route (e.g. an absolute @route) lies outside the group
+func groupRelativePath(group, routePath string) (string, bool) {
+	if routePath == group {
+		return "", true
+	}
+	if rel, ok := strings.CutPrefix(routePath, group+"/"); ok {
+		return "/" + rel, true
+	}
+	return "", false
+}
+
+// hasGroupMiddlewares reports whether any controller declares group middleware
+func (rg *routeGenerator) hasGroupMiddlewares() bool {
+	for _, r := range rg.routes {
+		if len(r.GroupMiddlewares) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// formatControllerGroups renders the ControllerGroups map of the reflection
+// router: one entry per grouped controller
+func (rg *routeGenerator) formatControllerGroups() string {
+	var builder strings.Builder
+	seen := make(map[string]bool)
+	for _, r := range rg.routes {
+		key := r.PkgPath + "." + r.Controller
+		if r.Group == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		builder.WriteString(fmt.Sprintf("\t\t\"%s\": {Prefix: %q, Middlewares: []gin.HandlerFunc{%s}},\n",
+			key, r.Group, strings.Join(middlewareExprs(r.GroupMiddlewares), ", ")))
+	}
+	return builder.String()
+}
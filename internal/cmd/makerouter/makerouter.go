@@ -5,12 +5,13 @@ package makerouter
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/jiajia556/god/internal/service"
 	"github.com/jiajia556/god/internal/template"
@@ -20,8 +21,12 @@ const (
 	generatedFileName    = "router.go"    // Output filename for generated router
 	controllerSuffix     = "Controller"   // Suffix for controller type names
 	controllerDirName    = "controller"   // Standard directory name for controllers
-	httpMethodAnnotation = "@http_method" // Annotation prefix for HTTP methods
-	middlewareAnnotation = "@middleware"  // Annotation prefix for middlewares
+	httpMethodAnnotation = "@http_method" // Annotation for HTTP methods
+	middlewareAnnotation = "@middleware"  // Annotation for middlewares
+	routeAnnotation      = "@route"       // Annotation for custom route paths
+	pathAnnotation       = "@path"        // Alias of @route
+	groupAnnotation      = "@group"       // Controller-level route group path
+	prefixAnnotation     = "@prefix"      // Alias of @group
 )
 
 // routeGenerator maintains state during route generation process
@@ -29,14 +34,44 @@ type routeGenerator struct {
 	imports           []string          // Import paths for controller packages
 	initRegistrations []string          // Controller registration statements
 	pkgAliases        map[string]string // Package import aliases
-	httpMethods       map[string]string // HTTP method mappings
+	httpMethods       map[string]string // HTTP method mappings, comma-separated verbs
 	middlewares       map[string]string // Middleware configurations
+	routePaths        map[string]string // Raw @route annotations
+	routes            []Route           // Resolved action routes, in discovery order
+	diagnostics       []Diagnostic      // Problems found during analysis
 	projectName       string            // Current project module name
 	projectRoot       string            // Current project root directory
+	rootPath          string            // API root directory being analyzed
+	cache             *parseCache       // Parsed files reused across runs, nil outside watch mode
 }
 
-// MakeRouter initiates the route generation process
-func MakeRouter(routerTemplate string, rootPath string) {
+// Route describes a single controller action resolved from source code
+type Route struct {
+	PkgPath          string   // Import path of the controller package
+	Controller       string   // Controller type name
+	Handler          string   // Action method name
+	HTTPMethods      []string // HTTP verbs from @http_method (default POST)
+	Middlewares      []string // Middleware names from the action's @middleware
+	Group            string   // Path of the controller's route group, "" when not grouped
+	GroupMiddlewares []string // Middleware names from the controller's @middleware
+	Path             string   // Full route path, e.g. api/user/list
+	Doc              []string // Doc comment lines of the action, without "//"
+	File             string   // Source file declaring the action
+	Line             int      // Line of the action declaration
+	Column           int      // Column of the action declaration
+
+	alias            string                    // Import alias of the controller package
+	custom           bool                      // Path comes from a @route annotation
+	annotations      map[string]token.Position // Position of each annotation line
+	groupAnnotations map[string]token.Position // Position of each controller annotation line
+}
+
+// MakeRouter initiates the route generation process.
+// When static is true the router template is expected to consume explicit
+// route bindings (RouteBindings) instead of the reflection based tag maps.
+// Diagnostics are printed; errors always abort generation, warnings only
+// when strict is set.
+func MakeRouter(routerTemplate string, rootPath string, static, strict bool) {
 	if rootPath == "" {
 		var err error
 		if rootPath, err = service.GetDefaultApiRoot(); err != nil {
@@ -44,52 +79,124 @@ func MakeRouter(routerTemplate string, rootPath string) {
 		}
 	}
 
+	if _, err := generateRouter(routerTemplate, rootPath, static, strict, nil); err != nil {
+		service.OutputFatal(err)
+	}
+}
+
+// generateRouter analyzes rootPath and writes router.go, leaving the file
+// untouched when its content would not change. cache may be nil; when set,
+// parsed controller files are reused between calls. It reports whether
+// router.go was written.
+func generateRouter(routerTemplate, rootPath string, static, strict bool, cache *parseCache) (bool, error) {
+	rg, err := newRouteGenerator()
+	if err != nil {
+		return false, err
+	}
+	rg.cache = cache
+
+	if err := rg.analyzeProjectStructure(rootPath); err != nil {
+		return false, fmt.Errorf("project analysis failed: %w", err)
+	}
+	rg.lint()
+	if failed := reportDiagnostics(rg.diagnostics, strict); failed {
+		return false, fmt.Errorf("router generation aborted")
+	}
+
+	tmplData := rg.generateTemplateData(rootPath, static)
+
+	outputPath := filepath.Join(rootPath, generatedFileName)
+	return template.CreateFileIfChanged(routerTemplate, tmplData, outputPath)
+}
+
+// Analyze resolves every route under rootPath without generating any file.
+// Routes are returned in discovery order; error diagnostics are reported as
+// an error, warnings are ignored.
+func Analyze(rootPath string) ([]Route, error) {
+	routes, diagnostics, err := Lint(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			problems = append(problems, d.String())
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid routes:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return routes, nil
+}
+
+// Lint resolves every route under rootPath and returns them together with
+// all diagnostics found. The error is only set when analysis itself fails.
+func Lint(rootPath string) ([]Route, []Diagnostic, error) {
+	if rootPath == "" {
+		var err error
+		if rootPath, err = service.GetDefaultApiRoot(); err != nil {
+			return nil, nil, fmt.Errorf("failed to get API root: %w", err)
+		}
+	}
+
+	rg, err := newRouteGenerator()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := rg.analyzeProjectStructure(rootPath); err != nil {
+		return nil, nil, fmt.Errorf("project analysis failed: %w", err)
+	}
+	rg.lint()
+	return rg.routes, rg.diagnostics, nil
+}
+
+// newRouteGenerator creates a generator bound to the current project
+func newRouteGenerator() (*routeGenerator, error) {
 	rg := &routeGenerator{
 		pkgAliases:  make(map[string]string),
 		httpMethods: make(map[string]string),
 		middlewares: make(map[string]string),
+		routePaths:  make(map[string]string),
 	}
 
 	var err error
 	if rg.projectName, err = service.GetProjectName(); err != nil {
-		service.OutputFatal("Failed to get project name:", err)
+		return nil, fmt.Errorf("failed to get project name: %w", err)
 	}
 
 	// Get project root (where gopackage.json or go.mod was discovered)
 	if rg.projectRoot, err = service.GetProjectRoot(); err != nil {
-		service.OutputFatal("Failed to get project root:", err)
-	}
-
-	tmplData, err := rg.generateTemplateData(rootPath)
-	if err != nil {
-		service.OutputFatal("Template data generation failed:", err)
-	}
-
-	outputPath := filepath.Join(rootPath, generatedFileName)
-	err = template.CreateFile(routerTemplate, tmplData, outputPath)
-	if err != nil {
-		service.OutputFatal(err)
+		return nil, fmt.Errorf("failed to get project root: %w", err)
 	}
+	return rg, nil
 }
 
-// generateTemplateData collects and prepares data for template generation
-func (rg *routeGenerator) generateTemplateData(root string) (template.RouterTmplData, error) {
-	if err := rg.analyzeProjectStructure(root); err != nil {
-		return template.RouterTmplData{}, fmt.Errorf("project analysis failed: %w", err)
+// generateTemplateData prepares data for template generation from the
+// analyzed project
+func (rg *routeGenerator) generateTemplateData(root string, static bool) template.RouterTmplData {
+	if static {
+		return template.RouterTmplData{
+			MiddlewareImportPath:  rg.staticMiddlewareImport(),
+			ControllersImportPath: strings.Join(rg.staticImports(), "\n"),
+			RouteBindings:         rg.formatRouteBindings(),
+		}
 	}
 
 	return template.RouterTmplData{
 		ApiRootDirName:        filepath.Base(root),
 		HTTPMethodTags:        rg.formatHTTPMethods(),
 		MiddlewareTags:        rg.formatMiddlewares(),
+		RoutePathTags:         rg.formatRoutePaths(),
+		ControllerGroupTags:   rg.formatControllerGroups(),
 		RegisterControllers:   strings.Join(rg.initRegistrations, ""),
 		MiddlewareImportPath:  rg.middlewareImport(),
 		ControllersImportPath: strings.Join(rg.imports, "\n\t"),
-	}, nil
+	}
 }
 
 // analyzeProjectStructure walks through project directories to find controllers
 func (rg *routeGenerator) analyzeProjectStructure(root string) error {
+	rg.rootPath = root
 	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			log.Printf("Directory access error: %v", err)
@@ -106,25 +213,62 @@ func (rg *routeGenerator) analyzeProjectStructure(root string) error {
 	})
 }
 
-// processControllerPackage processes all Go files in a controller directory
+// processControllerPackage processes every package under a controller
+// directory: the directory itself and its subdirectories
 func (rg *routeGenerator) processControllerPackage(dirPath string) error {
 	return filepath.WalkDir(dirPath, func(path string, d os.DirEntry, err error) error {
-		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".go") {
+		if err != nil || !d.IsDir() {
 			return err
 		}
-		return rg.analyzeControllerFile(path)
+		return rg.analyzeControllerDir(path, dirPath)
 	})
 }
 
-// analyzeControllerFile parses a single Go file for controller definitions
-func (rg *routeGenerator) analyzeControllerFile(filePath string) error {
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
+// controllerFile is a parsed Go file of a controller package
+type controllerFile struct {
+	path string
+	fset *token.FileSet
+	node *ast.File
+}
+
+// analyzeControllerDir parses the Go files of one package for controller
+// definitions. Methods are collected from every file of the package, not
+// only the one declaring the controller type, matching what the
+// reflection router registers. ctrlDir is the enclosing controller
+// directory, used to derive route paths.
+func (rg *routeGenerator) analyzeControllerDir(dirPath, ctrlDir string) error {
+	entries, err := os.ReadDir(dirPath)
 	if err != nil {
-		return fmt.Errorf("file parsing failed: %w", err)
+		return err
+	}
+	var files []controllerFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		path := filepath.Join(dirPath, name)
+		fset, node, err := rg.cache.parse(path)
+		if err != nil {
+			return fmt.Errorf("file parsing failed: %w", err)
+		}
+		files = append(files, controllerFile{path: path, fset: fset, node: node})
+	}
+	if len(files) == 0 {
+		return nil
 	}
 
-	pkgPath := constructImportPath(rg.projectName, rg.projectRoot, filePath)
+	pkgPath := constructImportPath(rg.projectName, rg.projectRoot, files[0].path)
+	for _, file := range files {
+		rg.analyzeControllerFile(file, files, pkgPath, ctrlDir)
+	}
+	return nil
+}
+
+// analyzeControllerFile registers the controllers declared in file, whose
+// package consists of files
+func (rg *routeGenerator) analyzeControllerFile(file controllerFile, files []controllerFile, pkgPath, ctrlDir string) {
+	fset, node := file.fset, file.node
 	alias, exists := rg.pkgAliases[pkgPath]
 
 	for _, decl := range node.Decls {
@@ -156,29 +300,113 @@ func (rg *routeGenerator) analyzeControllerFile(filePath string) error {
 			rg.initRegistrations = append(rg.initRegistrations,
 				fmt.Sprintf("\n\tRegisterController(%s{})", fullTypeName))
 
-			rg.extractAnnotations(node, controllerName, pkgPath+"."+controllerName)
+			tmpl := Route{PkgPath: pkgPath, alias: alias, Controller: controllerName,
+				Path: rg.baseRoute(ctrlDir, controllerName)}
+			doc := typeSpec.Doc
+			if doc == nil && len(genDecl.Specs) == 1 {
+				doc = genDecl.Doc
+			}
+			applyControllerAnnotations(fset, doc, &tmpl)
+			rg.extractAnnotations(files, controllerName, pkgPath+"."+controllerName, tmpl)
 		}
 	}
-	return nil
 }
 
-// extractAnnotations parses controller method annotations
-func (rg *routeGenerator) extractAnnotations(node *ast.File, typeName, pkgPrefix string) {
-	ast.Inspect(node, func(n ast.Node) bool {
-		fnDecl, ok := n.(*ast.FuncDecl)
-		if !ok || fnDecl.Recv == nil || len(fnDecl.Recv.List) == 0 {
-			return true
+// baseRoute derives the route prefix of a controller the same way the
+// generated router's buildBaseRoute does: api/<dirs>/<controller>
+func (rg *routeGenerator) baseRoute(ctrlDir, controllerName string) string {
+	parts := []string{"api"}
+	if rel, err := filepath.Rel(rg.rootPath, filepath.Dir(ctrlDir)); err == nil && rel != "." {
+		parts = append(parts, strings.Split(filepath.ToSlash(rel), "/")...)
+	}
+	parts = append(parts, lowerFirst(strings.TrimSuffix(controllerName, controllerSuffix)))
+	return strings.Join(parts, "/")
+}
+
+// extractAnnotations parses the annotations of the methods of typeName
+// declared in any file of its package, in file order.
+// tmpl carries the controller-level fields shared by every resolved route.
+func (rg *routeGenerator) extractAnnotations(files []controllerFile, typeName, pkgPrefix string, tmpl Route) {
+	for _, file := range files {
+		for _, decl := range file.node.Decls {
+			fnDecl, ok := decl.(*ast.FuncDecl)
+			if ok && fnDecl.Recv != nil && len(fnDecl.Recv.List) > 0 &&
+				extractReceiverType(fnDecl.Recv.List[0].Type) == typeName {
+				rg.extractMethod(file.fset, fnDecl, typeName, pkgPrefix, tmpl)
+			}
 		}
+	}
+}
 
-		recvType := extractReceiverType(fnDecl.Recv.List[0].Type)
-		if recvType != typeName {
-			return true
+// extractMethod resolves the route of a single controller method
+func (rg *routeGenerator) extractMethod(fset *token.FileSet, fnDecl *ast.FuncDecl, typeName, pkgPrefix string, tmpl Route) {
+	annotationKey := fmt.Sprintf("%s.%s", pkgPrefix, fnDecl.Name.Name)
+	pos := fset.Position(fnDecl.Pos())
+	annotations := annotationPositions(fset, fnDecl.Doc)
+	if err := rg.processMethodAnnotations(fnDecl, annotationKey); err != nil {
+		errPos := pos
+		if p, ok := annotations[httpMethodAnnotation]; ok {
+			errPos = p
 		}
+		rg.report(SeverityError, errPos, "%s.%s: %v", typeName, fnDecl.Name.Name, err)
+	}
 
-		annotationKey := fmt.Sprintf("%s.%s", pkgPrefix, fnDecl.Name.Name)
-		rg.processMethodAnnotations(fnDecl, annotationKey)
-		return true
-	})
+	switch {
+	case isHandlerMethod(fnDecl):
+		r := tmpl
+		r.Handler = fnDecl.Name.Name
+		r.HTTPMethods = strings.Split(rg.httpMethods[annotationKey], ",")
+		r.Middlewares = splitMiddlewares(rg.middlewares[annotationKey])
+		r.Path, r.custom = resolveRoutePath(tmpl.Path, fnDecl.Name.Name, rg.routePaths[annotationKey])
+		r.custom = r.custom || tmpl.custom
+		r.Doc = docLines(fnDecl.Doc)
+		r.File, r.Line, r.Column = pos.Filename, pos.Line, pos.Column
+		r.annotations = annotations
+		rg.routes = append(rg.routes, r)
+	case fnDecl.Name.IsExported():
+		rg.report(SeverityWarning, pos, "%s.%s is not a handler (want func(c *gin.Context)) and will not be routed",
+			typeName, fnDecl.Name.Name)
+	}
+}
+
+// isHandlerMethod reports whether fnDecl can be bound as a gin.HandlerFunc:
+// an exported method taking a single *gin.Context and returning nothing.
+func isHandlerMethod(fnDecl *ast.FuncDecl) bool {
+	if !fnDecl.Name.IsExported() || fnDecl.Type.Results != nil && len(fnDecl.Type.Results.List) > 0 {
+		return false
+	}
+	params := fnDecl.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) > 1 {
+		return false
+	}
+	star, ok := params[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Context"
+}
+
+// docLines returns the lines of a doc comment without comment markers
+func docLines(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	var lines []string
+	for _, comment := range doc.List {
+		lines = append(lines, strings.TrimSpace(strings.TrimPrefix(comment.Text, "//")))
+	}
+	return lines
+}
+
+// lowerFirst converts first letter to lowercase
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
 }
 
 // Helper functions below maintain the same logic with improved readability
@@ -240,30 +468,53 @@ func extractReceiverType(expr ast.Expr) string {
 	return ""
 }
 
-func (rg *routeGenerator) processMethodAnnotations(fnDecl *ast.FuncDecl, key string) {
+// processMethodAnnotations reads the annotations of an action. An invalid
+// @http_method keeps the POST default and is returned once the remaining
+// annotations are read.
+func (rg *routeGenerator) processMethodAnnotations(fnDecl *ast.FuncDecl, key string) error {
+	rg.httpMethods[key] = "POST"
 	if fnDecl.Doc == nil {
-		rg.httpMethods[key] = "POST"
-		return
+		return nil
 	}
+	var err error
 	for _, comment := range fnDecl.Doc.List {
-		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
-		switch {
-		case strings.HasPrefix(text, httpMethodAnnotation):
-			method := strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(text, httpMethodAnnotation)))
-			if method != "" {
-				rg.httpMethods[key] = method
+		name, value := splitAnnotation(comment.Text)
+		switch name {
+		case httpMethodAnnotation:
+			if value == "" {
+				continue
 			}
-		case strings.HasPrefix(text, middlewareAnnotation):
-			middlewares := strings.TrimSpace(strings.TrimPrefix(text, middlewareAnnotation))
-			if middlewares != "" {
-				rg.middlewares[key] = middlewares
+			methods, parseErr := service.ParseHTTPMethods(value)
+			if parseErr != nil {
+				err = parseErr
+				continue
+			}
+			rg.httpMethods[key] = strings.Join(methods, ",")
+		case routeAnnotation, pathAnnotation:
+			rg.routePaths[key] = value
+		case middlewareAnnotation:
+			if value != "" {
+				rg.middlewares[key] = value
 			}
 		}
 	}
+	return err
+}
+
+// formatRoutePaths renders the RoutePaths map of the reflection router,
+// holding the resolved path of every action with a @route annotation
+func (rg *routeGenerator) formatRoutePaths() string {
+	var builder strings.Builder
+	for _, r := range rg.routes {
+		if r.custom {
+			builder.WriteString(fmt.Sprintf("\t\t\"%s.%s.%s\": \"%s\",\n", r.PkgPath, r.Controller, r.Handler, r.Path))
+		}
+	}
+	return builder.String()
 }
 
 func (rg *routeGenerator) middlewareImport() string {
-	if len(rg.middlewares) > 0 {
+	if len(rg.middlewares) > 0 || rg.hasGroupMiddlewares() {
 		return fmt.Sprintf("\t\"%s/lib/middleware\"", rg.projectName)
 	}
 	return ""
@@ -271,28 +522,119 @@ func (rg *routeGenerator) middlewareImport() string {
 
 func (rg *routeGenerator) formatHTTPMethods() string {
 	var builder strings.Builder
-	for k, v := range rg.httpMethods {
-		builder.WriteString(fmt.Sprintf("\t\t\"%s\": \"%s\",\n", k, v))
+	for _, k := range sortedKeys(rg.httpMethods) {
+		builder.WriteString(fmt.Sprintf("\t\t\"%s\": \"%s\",\n", k, rg.httpMethods[k]))
 	}
 	return builder.String()
 }
 
 func (rg *routeGenerator) formatMiddlewares() string {
 	var builder strings.Builder
-	for k, v := range rg.middlewares {
-		v = strings.TrimSpace(v)
+	for _, k := range sortedKeys(rg.middlewares) {
+		v := strings.TrimSpace(rg.middlewares[k])
 		if v == "" {
 			continue
 		}
 
-		components := strings.Split(v, " ")
+		formatted := "{" + strings.Join(middlewareExprs(splitMiddlewares(v)), ", ") + "}"
+		builder.WriteString(fmt.Sprintf("\t\t\"%s\": %s,\n", k, formatted))
+	}
+	return builder.String()
+}
 
-		for i := 0; i < len(components); i++ {
-			components[i] = "middleware." + strings.TrimSpace(components[i])
+// staticImports returns import lines for controller packages that own at least
+// one route; packages without bindings would otherwise be unused imports.
+func (rg *routeGenerator) staticImports() []string {
+	var lines []string
+	seen := make(map[string]bool)
+	for _, r := range rg.routes {
+		if seen[r.PkgPath] {
+			continue
 		}
+		seen[r.PkgPath] = true
+		lines = append(lines, fmt.Sprintf("\t%s \"%s\"", r.alias, r.PkgPath))
+	}
+	return lines
+}
 
-		formatted := "{" + strings.Join(components, ", ") + "}"
-		builder.WriteString(fmt.Sprintf("\t\t\"%s\": %s,\n", k, formatted))
+func (rg *routeGenerator) staticMiddlewareImport() string {
+	for _, r := range rg.routes {
+		if len(r.Middlewares) > 0 || len(r.GroupMiddlewares) > 0 {
+			return rg.middlewareImport()
+		}
+	}
+	return ""
+}
+
+// formatRouteBindings renders one explicit router.<METHOD>(...) call per route,
+// grouped by controller so each controller value is constructed once.
+// Controllers with group annotations register through a gin.RouterGroup,
+// declared only when at least one of their routes stays inside the group.
+func (rg *routeGenerator) formatRouteBindings() string {
+	var builder strings.Builder
+	for i := 0; i < len(rg.routes); {
+		first := rg.routes[i]
+		end := i
+		for end < len(rg.routes) && rg.routes[end].PkgPath == first.PkgPath && rg.routes[end].Controller == first.Controller {
+			end++
+		}
+		builder.WriteString(fmt.Sprintf("\t{\n\t\tctrl := %s.%s{}\n", first.alias, first.Controller))
+		if usesGroup(rg.routes[i:end]) {
+			args := append([]string{fmt.Sprintf("%q", first.Group)}, middlewareExprs(first.GroupMiddlewares)...)
+			builder.WriteString(fmt.Sprintf("\t\tgroup := router.Group(%s)\n", strings.Join(args, ", ")))
+		}
+		for ; i < end; i++ {
+			builder.WriteString(formatBinding(rg.routes[i]))
+		}
+		builder.WriteString("\t}\n")
+	}
+	return builder.String()
+}
+
+// usesGroup reports whether any of the routes of a controller registers on
+// its route group rather than escaping it
+func usesGroup(routes []Route) bool {
+	for _, r := range routes {
+		if r.Group == "" {
+			continue
+		}
+		if _, ok := groupRelativePath(r.Group, r.Path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// formatBinding renders the registration statements of a single route.
+// Routes inside their controller's group are registered on the group with a
+// relative path; routes that escape it get the group middleware prepended.
+func formatBinding(r Route) string {
+	target, routePath := "router", r.Path
+	var handlers []string
+	if r.Group != "" {
+		if rel, ok := groupRelativePath(r.Group, r.Path); ok {
+			target, routePath = "group", rel
+		} else {
+			handlers = middlewareExprs(r.GroupMiddlewares)
+		}
+	}
+	handlers = append(handlers, middlewareExprs(r.Middlewares)...)
+	handlers = append(handlers, "ctrl."+r.Handler)
+	args := append([]string{fmt.Sprintf("%q", routePath)}, handlers...)
+
+	var builder strings.Builder
+	for _, m := range r.HTTPMethods {
+		builder.WriteString(fmt.Sprintf("\t\t%s.%s(%s)\n", target, m, strings.Join(args, ", ")))
 	}
 	return builder.String()
 }
+
+// sortedKeys returns map keys in lexical order so generated output is stable
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
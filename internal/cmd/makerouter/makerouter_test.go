@@ -0,0 +1,313 @@
+package makerouter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testProject = "example.com/routes"
+
+// ginStub declares the parts of gin the generated routers use
+const ginStub = `package gin
+
+type Context struct{}
+
+type HandlerFunc func(*Context)
+
+type RouterGroup struct{}
+
+func (g *RouterGroup) Group(path string, handlers ...HandlerFunc) *RouterGroup { return g }
+func (g *RouterGroup) GET(path string, handlers ...HandlerFunc)                {}
+func (g *RouterGroup) POST(path string, handlers ...HandlerFunc)               {}
+func (g *RouterGroup) PUT(path string, handlers ...HandlerFunc)                {}
+func (g *RouterGroup) PATCH(path string, handlers ...HandlerFunc)              {}
+func (g *RouterGroup) DELETE(path string, handlers ...HandlerFunc)             {}
+func (g *RouterGroup) HEAD(path string, handlers ...HandlerFunc)               {}
+func (g *RouterGroup) OPTIONS(path string, handlers ...HandlerFunc)            {}
+
+type Engine struct{ RouterGroup }
+`
+
+var (
+	projectRoot string
+	// sourceDir is the directory of this package, read before TestMain
+	// changes the working directory
+	sourceDir, _ = os.Getwd()
+)
+
+// TestMain runs the tests inside a project directory: the project name and
+// root are looked up once per process
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "makerouter-test-*")
+	if err != nil {
+		panic(err)
+	}
+	projectRoot = dir
+	pkg := `{"project_name": "` + testProject + `", "default_api_root": "app/api/home"}`
+	if err := os.WriteFile(filepath.Join(dir, "gopackage.json"), []byte(pkg), 0o644); err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// writeAPI writes the controller files of an API root named after the test
+// and returns the root
+func writeAPI(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := filepath.Join(projectRoot, "app", strings.ToLower(t.Name()))
+	for name, src := range files {
+		path := filepath.Join(root, controllerDirName, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// makeStaticRouter runs `god mkrt --static` on root and type-checks the
+// generated router against the controllers and a gin stub, failing the
+// test on any compile error. It returns the router source.
+func makeStaticRouter(t *testing.T, root string) string {
+	t.Helper()
+	tmpl, err := os.ReadFile(filepath.Join(sourceDir, "..", "..", "..", "templates", "basic", "app", "api", "home", "router_static.go.tmpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generateRouter(string(tmpl), root, true, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	imports := stubImporter{}
+	imports["github.com/gin-gonic/gin"] = typeCheck(t, imports, "github.com/gin-gonic/gin", map[string]string{"gin.go": ginStub})
+	ctrlDir := filepath.Join(root, controllerDirName)
+	imports[constructImportPath(testProject, projectRoot, filepath.Join(ctrlDir, "x.go"))] = typeCheck(t, imports, "controller", readDir(t, ctrlDir))
+
+	src, err := os.ReadFile(filepath.Join(root, generatedFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeCheck(t, imports, "main", map[string]string{generatedFileName: string(src)})
+	return string(src)
+}
+
+type stubImporter map[string]*types.Package
+
+func (s stubImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := s[path]; ok {
+		return pkg, nil
+	}
+	if pkg, err := importer.Default().Import(path); err == nil {
+		return pkg, nil
+	}
+	return nil, fmt.Errorf("package %s is not stubbed", path)
+}
+
+func readDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, e := range entries {
+		src, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[e.Name()] = string(src)
+	}
+	return files
+}
+
+// typeCheck compiles the given files as one package
+func typeCheck(t *testing.T, imports stubImporter, path string, files map[string]string) *types.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for name, src := range files {
+		f, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, f)
+	}
+	var errs []string
+	conf := types.Config{Importer: imports, Error: func(err error) { errs = append(errs, err.Error()) }}
+	pkg, _ := conf.Check(path, fset, parsed, nil)
+	if len(errs) > 0 {
+		t.Fatalf("%s does not compile:\n%s\n%s", path, strings.Join(errs, "\n"), files[generatedFileName])
+	}
+	return pkg
+}
+
+func TestStaticRouterCollectsMethodsFromAllPackageFiles(t *testing.T) {
+	root := writeAPI(t, map[string]string{
+		"user.go": `package controller
+
+import "github.com/gin-gonic/gin"
+
+type UserController struct{}
+
+func (UserController) List(c *gin.Context) {}
+`,
+		"user_detail.go": `package controller
+
+import "github.com/gin-gonic/gin"
+
+// @http_method GET
+func (UserController) Detail(c *gin.Context) {}
+`,
+	})
+	src := makeStaticRouter(t, root)
+	for _, want := range []string{`router.POST("api/user/list", ctrl.List)`, `router.GET("api/user/detail", ctrl.Detail)`} {
+		if !strings.Contains(src, want) {
+			t.Errorf("router is missing %s:\n%s", want, src)
+		}
+	}
+
+	routes, err := Analyze(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 2 {
+		t.Fatalf("Analyze found %d routes, want 2: %+v", len(routes), routes)
+	}
+	if got := filepath.Base(routes[1].File); got != "user_detail.go" {
+		t.Errorf("Detail is reported in %s, want user_detail.go", got)
+	}
+}
+
+func TestStaticRouterGroupWithoutGroupedRoutes(t *testing.T) {
+	root := writeAPI(t, map[string]string{
+		"order.go": `package controller
+
+import "github.com/gin-gonic/gin"
+
+// @prefix /v2/orders
+type OrderController struct{}
+
+// @http_method GET
+// @route /health
+func (OrderController) Health(c *gin.Context) {}
+
+// @route /v1/orders/create
+func (OrderController) Create(c *gin.Context) {}
+`,
+		"item.go": `package controller
+
+import "github.com/gin-gonic/gin"
+
+// @group /v2/items
+type ItemController struct{}
+
+// @http_method GET
+// @route :id
+func (ItemController) Get(c *gin.Context) {}
+
+// @route /v1/items/create
+func (ItemController) Create(c *gin.Context) {}
+`,
+	})
+	src := makeStaticRouter(t, root)
+	if strings.Count(src, "router.Group(") != 1 {
+		t.Errorf("want a group for ItemController only:\n%s", src)
+	}
+}
+
+func TestMethodAnnotations(t *testing.T) {
+	root := writeAPI(t, map[string]string{
+		"user.go": `package controller
+
+import "github.com/gin-gonic/gin"
+
+type UserController struct{}
+
+// @routes /all
+// @router /any
+// @http_method FETCH
+// @route	/member/:id
+// @middlewares audit
+// @middleware auth
+func (UserController) Detail(c *gin.Context) {}
+`,
+	})
+	routes, diagnostics, err := Lint(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatalf("Lint found %d routes, want 1: %+v", len(routes), routes)
+	}
+	r := routes[0]
+	if r.Path != "member/:id" || strings.Join(r.HTTPMethods, ",") != "POST" || strings.Join(r.Middlewares, ",") != "Auth" {
+		t.Errorf("got %s %v with middlewares %v, want POST member/:id with Auth", r.Path, r.HTTPMethods, r.Middlewares)
+	}
+	var methodErrors []string
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError && strings.Contains(d.Message, "FETCH") {
+			methodErrors = append(methodErrors, d.String())
+		}
+	}
+	if len(methodErrors) != 1 || !strings.Contains(methodErrors[0], "user.go:9:") {
+		t.Errorf("want one error at the @http_method line, got %q", methodErrors)
+	}
+}
+
+func TestSplitAnnotation(t *testing.T) {
+	tests := []struct {
+		comment     string
+		name, value string
+	}{
+		{"// @route /login", "@route", "/login"},
+		{"//@route\t/login ", "@route", "/login"},
+		{"// @router /login", "@router", "/login"},
+		{"// @group", "@group", ""},
+		{"// Login signs in @route", "", ""},
+	}
+	for _, tt := range tests {
+		name, value := splitAnnotation(tt.comment)
+		if name != tt.name || value != tt.value {
+			t.Errorf("splitAnnotation(%q) = %q, %q, want %q, %q", tt.comment, name, value, tt.name, tt.value)
+		}
+	}
+}
+
+func TestParseCacheReparsesIntoFreshFileSet(t *testing.T) {
+	root := writeAPI(t, map[string]string{"user.go": "package controller\n\ntype UserController struct{}\n"})
+	path := filepath.Join(root, controllerDirName, "user.go")
+	cache := newParseCache()
+
+	fset1, node1, err := cache.parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fset, node, _ := cache.parse(path); fset != fset1 || node != node1 {
+		t.Error("unchanged file was parsed again")
+	}
+	if err := os.WriteFile(path, []byte("package controller\n\n// UserController handles users\ntype UserController struct{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fset2, _, err := cache.parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fset2 == fset1 {
+		t.Error("changed file was parsed into the previous FileSet")
+	}
+}
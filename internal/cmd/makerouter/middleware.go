@@ -0,0 +1,223 @@
+package makerouter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jiajia556/god/internal/service"
+)
+
+// middlewareDir is the project-relative package middleware names resolve to
+const middlewareDir = "lib/middleware"
+
+// middlewareKind tells how an exported lib/middleware identifier is used
+type middlewareKind int
+
+const (
+	middlewareHandler middlewareKind = iota // func(c *gin.Context), used as-is
+	middlewareFactory                       // func(args...) gin.HandlerFunc, must be called
+	middlewareValue                         // package-level var, used as-is
+)
+
+// middlewareDecl describes an exported lib/middleware identifier
+type middlewareDecl struct {
+	kind     middlewareKind
+	params   int  // Number of parameters of a factory
+	variadic bool // Factory accepts a variable number of arguments
+}
+
+// splitMiddlewares splits a @middleware value into its entries. Entries are
+// separated by whitespace outside parentheses and quotes, so factory calls
+// such as RequireRole("admin", "root") stay intact. Each entry is normalized
+// to start with an upper-case letter, e.g. auth -> Auth.
+func splitMiddlewares(spec string) []string {
+	var (
+		entries []string
+		cur     strings.Builder
+		depth   int
+		quote   rune
+		escaped bool
+	)
+	flush := func() {
+		if cur.Len() > 0 {
+			entries = append(entries, service.CapitalizeFirstLetter(cur.String()))
+			cur.Reset()
+		}
+	}
+	for _, c := range spec {
+		switch {
+		case quote != 0:
+			if escaped {
+				escaped = false
+			} else if c == '\\' && quote != '`' {
+				escaped = true
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case (c == ' ' || c == '\t' || c == ',') && depth == 0:
+			flush()
+			continue
+		}
+		cur.WriteRune(c)
+	}
+	flush()
+	return entries
+}
+
+// middlewareExprs converts @middleware entries to Go expressions
+func middlewareExprs(entries []string) []string {
+	exprs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		exprs = append(exprs, "middleware."+entry)
+	}
+	return exprs
+}
+
+// parseMiddleware parses an entry into its name and call arguments.
+// isCall reports whether the entry is a factory call like RateLimit(100).
+func parseMiddleware(entry string) (name string, args int, isCall bool, err error) {
+	expr, err := parser.ParseExpr(entry)
+	if err != nil {
+		return "", 0, false, fmt.Errorf("malformed middleware %q", entry)
+	}
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name, 0, false, nil
+	case *ast.CallExpr:
+		if ident, ok := e.Fun.(*ast.Ident); ok {
+			return ident.Name, len(e.Args), true, nil
+		}
+	}
+	return "", 0, false, fmt.Errorf("malformed middleware %q, want Name or Name(args...)", entry)
+}
+
+// checkMiddlewares validates @middleware entries against the exported
+// identifiers of the project's lib/middleware package
+func (rg *routeGenerator) checkMiddlewares() {
+	decls := middlewareDecls(filepath.Join(rg.projectRoot, middlewareDir))
+	reported := make(map[string]bool)
+	for _, r := range rg.routes {
+		for _, m := range r.Middlewares {
+			if err := checkMiddleware(decls, m); err != nil {
+				rg.report(middlewareSeverity(err), r.position(middlewareAnnotation), "%s.%s: %v", r.Controller, r.Handler, err)
+			}
+		}
+
+		// controller middleware is shared by all actions; report it once
+		key := r.PkgPath + "." + r.Controller
+		if reported[key] {
+			continue
+		}
+		reported[key] = true
+		for _, m := range r.GroupMiddlewares {
+			if err := checkMiddleware(decls, m); err != nil {
+				rg.report(middlewareSeverity(err), r.groupAnnotations[middlewareAnnotation], "%s: %v", r.Controller, err)
+			}
+		}
+	}
+}
+
+// malformedMiddlewareError marks entries that cannot be turned into Go code
+type malformedMiddlewareError struct{ error }
+
+func middlewareSeverity(err error) Severity {
+	if _, ok := err.(malformedMiddlewareError); ok {
+		return SeverityError
+	}
+	return SeverityWarning
+}
+
+// checkMiddleware validates a single entry against the known declarations
+func checkMiddleware(decls map[string]middlewareDecl, entry string) error {
+	name, args, isCall, err := parseMiddleware(entry)
+	if err != nil {
+		return malformedMiddlewareError{err}
+	}
+	decl, ok := decls[name]
+	if !ok {
+		return fmt.Errorf("middleware %q is not exported by %s", name, middlewareDir)
+	}
+	switch {
+	case isCall && decl.kind != middlewareFactory:
+		return fmt.Errorf("middleware %q is not a factory and cannot be called", name)
+	case !isCall && decl.kind == middlewareFactory:
+		return fmt.Errorf("middleware %q is a factory; call it, e.g. %s(...)", name, name)
+	case isCall && decl.variadic && args < decl.params-1:
+		return fmt.Errorf("middleware %s expects at least %d argument(s), got %d", name, decl.params-1, args)
+	case isCall && !decl.variadic && args != decl.params:
+		return fmt.Errorf("middleware %s expects %d argument(s), got %d", name, decl.params, args)
+	}
+	return nil
+}
+
+// middlewareDecls collects the exported package-level funcs and vars
+// declared in dir; a missing or unparsable directory yields none
+func middlewareDecls(dir string) map[string]middlewareDecl {
+	decls := make(map[string]middlewareDecl)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return decls
+	}
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, e.Name()), nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil && d.Name.IsExported() {
+					decls[d.Name.Name] = funcMiddlewareDecl(d.Type)
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					vs, ok := spec.(*ast.ValueSpec)
+					if !ok {
+						continue
+					}
+					for _, name := range vs.Names {
+						if name.IsExported() {
+							decls[name.Name] = middlewareDecl{kind: middlewareValue}
+						}
+					}
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// funcMiddlewareDecl classifies a function: anything returning a value is a
+// factory, everything else is used directly as a handler
+func funcMiddlewareDecl(ft *ast.FuncType) middlewareDecl {
+	if ft.Results == nil || len(ft.Results.List) == 0 {
+		return middlewareDecl{kind: middlewareHandler}
+	}
+	decl := middlewareDecl{kind: middlewareFactory}
+	for _, p := range ft.Params.List {
+		n := len(p.Names)
+		if n == 0 {
+			n = 1
+		}
+		decl.params += n
+		if _, ok := p.Type.(*ast.Ellipsis); ok {
+			decl.variadic = true
+		}
+	}
+	return decl
+}
@@ -0,0 +1,131 @@
+package makerouter
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/jiajia556/god/internal/service"
+)
+
+// resolveRoutePath computes the full path of an action.
+// Without annotation the path is <base>/<method>; an annotation starting with
+// "/" replaces the derived path entirely, any other annotation is appended to
+// the controller base route. The second result reports whether an annotation
+// was applied.
+func resolveRoutePath(base, method, annotation string) (string, bool) {
+	if annotation == "" {
+		return base + "/" + lowerFirst(method), false
+	}
+	if strings.HasPrefix(annotation, "/") {
+		return strings.TrimPrefix(path.Clean(annotation), "/"), true
+	}
+	return strings.TrimPrefix(path.Clean(base+"/"+annotation), "/"), true
+}
+
+// validateRoutePath checks that every placeholder in p is well-formed:
+// ":name" or "*name" covering a whole segment, a catch-all only as the last
+// segment, and no placeholder name used twice.
+func validateRoutePath(p string) error {
+	if p == "" || p == "." {
+		return fmt.Errorf("empty route path")
+	}
+	segments := strings.Split(p, "/")
+	names := make(map[string]bool)
+	for i, seg := range segments {
+		if idx := strings.IndexAny(seg, ":*"); idx > 0 {
+			return fmt.Errorf("placeholder must start a path segment in %q", seg)
+		}
+		if !isPlaceholder(seg) {
+			continue
+		}
+		name := seg[1:]
+		if !isIdentifier(name) {
+			return fmt.Errorf("malformed placeholder %q", seg)
+		}
+		if seg[0] == '*' && i != len(segments)-1 {
+			return fmt.Errorf("catch-all %q must be the last path segment", seg)
+		}
+		if names[name] {
+			return fmt.Errorf("duplicate placeholder %q", name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+// routeConflict reports why two paths registered for the same HTTP method
+// cannot coexist in gin's routing tree, or "" if they can.
+func routeConflict(a, b string) string {
+	sa, sb := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(sa) && i < len(sb); i++ {
+		x, y := sa[i], sb[i]
+		switch {
+		case strings.HasPrefix(x, "*") || strings.HasPrefix(y, "*"):
+			if x == y && len(sa) == len(sb) {
+				return "duplicate route"
+			}
+			return fmt.Sprintf("catch-all %q conflicts with %q", x, y)
+		case strings.HasPrefix(x, ":") && strings.HasPrefix(y, ":"):
+			if x != y {
+				return fmt.Sprintf("placeholder %q conflicts with %q", x, y)
+			}
+		case x != y:
+			return ""
+		}
+	}
+	if len(sa) == len(sb) {
+		return "duplicate route"
+	}
+	return ""
+}
+
+// validateRoutes checks every resolved route path and reports conflicts
+// between routes, possibly declared by different controllers
+func (rg *routeGenerator) validateRoutes() {
+	var checked []Route
+	for _, r := range rg.routes {
+		if err := validateRoutePath(r.Path); err != nil {
+			rg.report(SeverityError, r.position(routeAnnotation, pathAnnotation), "%s.%s: %v", r.Controller, r.Handler, err)
+			continue
+		}
+		for _, o := range checked {
+			if !sharesMethod(r, o) {
+				continue
+			}
+			if reason := routeConflict(r.Path, o.Path); reason != "" {
+				rg.report(SeverityError, r.position(routeAnnotation, pathAnnotation),
+					"%s.%s (%s): %s with %s.%s (%s) at %s:%d",
+					r.Controller, r.Handler, r.Path, reason, o.Controller, o.Handler, o.Path, o.File, o.Line)
+			}
+		}
+		checked = append(checked, r)
+	}
+}
+
+// sharesMethod reports whether two routes are registered for a common verb
+func sharesMethod(a, b Route) bool {
+	for _, m := range a.HTTPMethods {
+		if service.InArray(b.HTTPMethods, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPlaceholder(seg string) bool {
+	return strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*")
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9' {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package makerouter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveRoutePath(t *testing.T) {
+	tests := []struct {
+		base, method, annotation string
+		want                     string
+		annotated                bool
+	}{
+		{"api/user", "List", "", "api/user/list", false},
+		{"api/user", "GetByID", "", "api/user/getByID", false},
+		{"api/user", "Detail", ":id", "api/user/:id", true},
+		{"api/user", "Detail", "detail/:id/", "api/user/detail/:id", true},
+		{"api/user", "Health", "/health", "health", true},
+		{"api/user", "Login", "/v1//auth/../login", "v1/login", true},
+		{"api/user", "Parent", "../account", "api/account", true},
+		{"api/user", "Files", "*path", "api/user/*path", true},
+	}
+	for _, tt := range tests {
+		got, annotated := resolveRoutePath(tt.base, tt.method, tt.annotation)
+		if got != tt.want || annotated != tt.annotated {
+			t.Errorf("resolveRoutePath(%q, %q, %q) = %q, %v; want %q, %v",
+				tt.base, tt.method, tt.annotation, got, annotated, tt.want, tt.annotated)
+		}
+	}
+}
+
+func TestGroupRelativePath(t *testing.T) {
+	tests := []struct {
+		group, path string
+		want        string
+		ok          bool
+	}{
+		{"v2/items", "v2/items/:id", "/:id", true},
+		{"v2/items", "v2/items", "", true},
+		{"v2/items", "v2/items/a/b", "/a/b", true},
+		{"v2/items", "v2/itemsx/a", "", false},
+		{"v2/items", "v1/items/create", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := groupRelativePath(tt.group, tt.path)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("groupRelativePath(%q, %q) = %q, %v; want %q, %v", tt.group, tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestValidateRoutePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string // error prefix, empty when valid
+	}{
+		{"api/user/:id", ""},
+		{"api/files/*path", ""},
+		{"", "empty route path"},
+		{"api/user:id", "placeholder must start"},
+		{"api/:1id", "malformed placeholder"},
+		{"api/*path/x", "catch-all"},
+		{"api/:id/x/:id", "duplicate placeholder"},
+	}
+	for _, tt := range tests {
+		err := validateRoutePath(tt.path)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("validateRoutePath(%q) = %v, want no error", tt.path, err)
+		case tt.want != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.want)):
+			t.Errorf("validateRoutePath(%q) = %v, want %s...", tt.path, err, tt.want)
+		}
+	}
+}
+
+func TestRouteConflict(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string // reason prefix, empty when the routes coexist
+	}{
+		{"api/user/list", "api/user/detail", ""},
+		{"api/user/list", "api/user/list", "duplicate route"},
+		{"api/user/:id", "api/user/:name", "placeholder"},
+		{"api/user/:id", "api/user/:id/posts", ""},
+		{"api/files/*path", "api/files/readme", "catch-all"},
+		{"api/user", "api/user/list", ""},
+	}
+	for _, tt := range tests {
+		got := routeConflict(tt.a, tt.b)
+		if tt.want == "" && got != "" || !strings.HasPrefix(got, tt.want) {
+			t.Errorf("routeConflict(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
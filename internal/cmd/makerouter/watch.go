@@ -0,0 +1,168 @@
+package makerouter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jiajia556/god/internal/service"
+)
+
+// parseCache keeps parsed controller files between watch iterations so only
+// files whose modification time or size changed are parsed again. Each
+// parse gets a fresh FileSet, dropped together with its file, so memory
+// does not grow over a long watch session.
+type parseCache struct {
+	files map[string]cachedFile
+}
+
+type cachedFile struct {
+	stamp service.FileStamp
+	fset  *token.FileSet
+	node  *ast.File
+}
+
+func newParseCache() *parseCache {
+	return &parseCache{files: make(map[string]cachedFile)}
+}
+
+// parse returns the AST of path with comments. A nil cache always parses.
+func (c *parseCache) parse(path string) (*token.FileSet, *ast.File, error) {
+	if c == nil {
+		fset := token.NewFileSet()
+		node, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		return fset, node, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	stamp := service.FileStamp{ModTime: info.ModTime(), Size: info.Size()}
+	if cached, ok := c.files[path]; ok && cached.stamp == stamp {
+		return cached.fset, cached.node, nil
+	}
+
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		delete(c.files, path)
+		return nil, nil, err
+	}
+	c.files[path] = cachedFile{stamp: stamp, fset: fset, node: node}
+	return fset, node, nil
+}
+
+// retain drops cached files that are no longer part of the snapshot
+func (c *parseCache) retain(snapshot map[string]service.FileStamp) {
+	for path := range c.files {
+		if _, ok := snapshot[path]; !ok {
+			delete(c.files, path)
+		}
+	}
+}
+
+// Regenerator regenerates router.go on demand, reusing parsed controller
+// files between runs. It backs `god mkrt --watch` and `god dev`.
+type Regenerator struct {
+	routerTemplate string
+	rootPath       string
+	static         bool
+	strict         bool
+	roots          []string
+	cache          *parseCache
+}
+
+// NewRegenerator creates a Regenerator for the API root rootPath, which
+// defaults to the project's default API root when empty
+func NewRegenerator(routerTemplate string, rootPath string, static, strict bool) (*Regenerator, error) {
+	if rootPath == "" {
+		var err error
+		if rootPath, err = service.GetDefaultApiRoot(); err != nil {
+			return nil, fmt.Errorf("failed to get API root: %w", err)
+		}
+	}
+	projectRoot, err := service.GetProjectRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project root: %w", err)
+	}
+	return &Regenerator{
+		routerTemplate: routerTemplate,
+		rootPath:       rootPath,
+		static:         static,
+		strict:         strict,
+		roots:          []string{rootPath, filepath.Join(projectRoot, middlewareDir)},
+		cache:          newParseCache(),
+	}, nil
+}
+
+// RouterPath returns the path of the generated router file
+func (r *Regenerator) RouterPath() string {
+	return filepath.Join(r.rootPath, generatedFileName)
+}
+
+// Snapshot stamps every file whose change can affect the generated router:
+// Go files inside controller directories and lib/middleware
+func (r *Regenerator) Snapshot() map[string]service.FileStamp {
+	return service.ScanFiles(r.roots, isWatchedFile)
+}
+
+// Run analyzes the controllers and writes router.go when its content
+// changes, reporting whether it was written. Diagnostics are printed.
+func (r *Regenerator) Run() (bool, error) {
+	r.cache.retain(r.Snapshot())
+	return generateRouter(r.routerTemplate, r.rootPath, r.static, r.strict, r.cache)
+}
+
+// WatchRouter generates router.go like MakeRouter, then polls the controller
+// directories under rootPath and the project's lib/middleware every interval,
+// regenerating the router whenever a file is added, removed or modified.
+// router.go is only rewritten when its content changes. Failures are printed
+// and the watch goes on; it only returns when the process is interrupted.
+func WatchRouter(routerTemplate string, rootPath string, static, strict bool, interval time.Duration) {
+	rg, err := NewRegenerator(routerTemplate, rootPath, static, strict)
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	regenerate := func() {
+		written, err := rg.Run()
+		switch {
+		case err != nil:
+			service.OutputErrorf("%v", err)
+		case written:
+			service.OutputInfof("%s updated", rg.RouterPath())
+		default:
+			service.OutputInfof("%s is up to date", rg.RouterPath())
+		}
+	}
+
+	snapshot := rg.Snapshot()
+	regenerate()
+	service.OutputInfof("Watching %s for controller changes (Ctrl+C to stop)", rg.rootPath)
+
+	for range time.Tick(interval) {
+		next := rg.Snapshot()
+		changed := service.ChangedFiles(snapshot, next)
+		snapshot = next
+		if len(changed) == 0 {
+			continue
+		}
+		service.OutputInfof("Changed: %s", strings.Join(changed, ", "))
+		regenerate()
+	}
+}
+
+// isWatchedFile reports whether a change to path can affect the generated
+// router: Go files inside a controller directory or lib/middleware
+func isWatchedFile(path string) bool {
+	if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+		return false
+	}
+	slashed := filepath.ToSlash(path)
+	return strings.Contains(slashed, "/"+controllerDirName+"/") || strings.Contains(slashed, "/"+middlewareDir+"/")
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"github.com/jiajia556/god/internal/cmd/migrate"
+	"github.com/jiajia556/god/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage SQL migrations",
+	Long:  `Generate SQL migration files and apply them to the project's database.`,
+}
+
+// migrateDiffCmd writes the migration between two schemas
+var migrateDiffCmd = &cobra.Command{
+	Use:     "diff [name]",
+	Short:   "Generate up/down migration files from a schema diff",
+	Long:    "Compares two schemas and writes the ALTER TABLE, CREATE and DROP statements between them to\n<version>_<name>.up.sql and <version>_<name>.down.sql, for review before they are applied.\n--from and --to take a SQL file, a directory of migrations or a database DSN; either may be left\nout to use the models under model/.",
+	Example: "  god migrate diff --from schema_old.sql --to schema.sql\n  god migrate diff add_orders --from migrations\n  god migrate diff --from \"root:secret@tcp(127.0.0.1:3306)/shop\" --dry-run",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := migrate.DiffOptions{}
+		if len(args) > 0 {
+			opts.Name = args[0]
+		}
+		opts.From, _ = cmd.Flags().GetString("from")
+		opts.To, _ = cmd.Flags().GetString("to")
+		opts.Dir, _ = cmd.Flags().GetString("dir")
+		opts.Dialect, _ = cmd.Flags().GetString("dialect")
+		opts.Tables, _ = cmd.Flags().GetStringSlice("tables")
+		opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+		migrate.Diff(opts)
+	},
+}
+
+// migrateNewCmd writes an empty migration
+var migrateNewCmd = &cobra.Command{
+	Use:     "new [name]",
+	Short:   "Create an empty pair of up/down migration files",
+	Long:    "Creates <version>_<name>.up.sql and <version>_<name>.down.sql in the migrations directory,\nwith the current time as version.",
+	Example: "  god migrate new add_orders\n  god migrate new \"backfill order totals\" --dir db/migrations",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+		migrate.New(args[0], dir)
+	},
+}
+
+// migrateUpCmd applies the pending migrations
+var migrateUpCmd = &cobra.Command{
+	Use:     "up",
+	Short:   "Apply the pending migrations",
+	Long:    "Applies the pending migrations in version order, each in a transaction, and records them in the\nschema_migrations table. Refuses to run when an applied migration file has changed since.\nRuns the project's app/migrate program, which is created when missing.",
+	Example: "  god migrate up\n  god migrate up --config ./config.prod.yaml\n  god migrate up --steps 1",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runMigrations(cmd, "up")
+	},
+}
+
+// migrateDownCmd reverts the last applied migrations
+var migrateDownCmd = &cobra.Command{
+	Use:     "down",
+	Short:   "Revert the last applied migration",
+	Long:    "Runs the down file of the last applied migration, or of the last --steps migrations, and removes\nthem from the schema_migrations table.",
+	Example: "  god migrate down\n  god migrate down --steps 3",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runMigrations(cmd, "down")
+	},
+}
+
+// migrateStatusCmd lists the migrations
+var migrateStatusCmd = &cobra.Command{
+	Use:     "status",
+	Short:   "List the migrations and whether they are applied",
+	Long:    "Lists the migration files and the applied migrations, flagging applied files that changed or are gone.",
+	Example: "  god migrate status\n  god migrate status --config ./config.prod.yaml",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runMigrations(cmd, "status")
+	},
+}
+
+// runMigrations runs the project's migration program
+func runMigrations(cmd *cobra.Command, command string) {
+	runnerContent, err := templateFS.ReadFile("templates/basic/lib/db/migrate/migrate.go.tmpl")
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	mainContent, err := templateFS.ReadFile("templates/basic/app/migrate/main.go.tmpl")
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	opts := migrate.RunOptions{}
+	opts.Config, _ = cmd.Flags().GetString("config")
+	opts.Dir, _ = cmd.Flags().GetString("dir")
+	if cmd.Flags().Lookup("steps") != nil {
+		opts.Steps, _ = cmd.Flags().GetInt("steps")
+	}
+	migrate.Run(command, opts, migrate.Templates{Runner: string(runnerContent), Main: string(mainContent)})
+}
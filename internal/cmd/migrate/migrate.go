@@ -0,0 +1,262 @@
+// Package migrate generates SQL migration files and applies them through
+// the project's migration program
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jiajia556/god/internal/service"
+	"github.com/jiajia556/god/internal/template"
+)
+
+// DiffOptions selects the schemas to compare and where the migration goes
+type DiffOptions struct {
+	From    string   // SQL file, migrations directory or DSN of the current schema; empty reads the models
+	To      string   // Same for the desired schema
+	Dir     string   // Directory to write the migration files to; empty is <project>/migrations
+	Name    string   // Name of the migration, after its version
+	Dialect string   // SQL dialect; empty defaults to the project's db_dialect
+	Tables  []string // Globs of tables to compare, !glob to skip tables
+	DryRun  bool     // Print the migration instead of writing it
+}
+
+// Diff compares two schemas and writes the statements that migrate the
+// first to the second, and back, as <version>_<name>.up.sql and
+// <version>_<name>.down.sql with a timestamp version.
+func Diff(opts DiffOptions) {
+	if opts.From == "" && opts.To == "" {
+		service.OutputFatal("--from or --to is required; the other one defaults to the models")
+	}
+	var err error
+	if opts.Dialect == "" {
+		opts.Dialect, err = service.GetDBDialect()
+	} else {
+		opts.Dialect, err = service.ParseDialect(opts.Dialect)
+	}
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	from := loadSchema(opts.From, opts.Dialect, opts.Tables)
+	to := loadSchema(opts.To, opts.Dialect, opts.Tables)
+
+	up := service.MigrationSQL(from, to, opts.Dialect)
+	if up == "" {
+		service.OutputInfof("No schema changes between %s and %s", describeSource(opts.From), describeSource(opts.To))
+		return
+	}
+	down := service.MigrationSQL(to, from, opts.Dialect)
+
+	header := fmt.Sprintf("-- Generated by god migrate diff (%s): %s -> %s\n\n", opts.Dialect, describeSource(opts.From), describeSource(opts.To))
+	if opts.DryRun {
+		fmt.Print("-- up\n" + up + "\n-- down\n" + down)
+		return
+	}
+	dir := opts.Dir
+	if dir == "" {
+		dir = migrationsDir()
+	}
+	base := filepath.Join(dir, newVersion()+"_"+migrationName(opts.Name))
+	for _, file := range []struct{ path, sql string }{{base + ".up.sql", up}, {base + ".down.sql", down}} {
+		if err := template.WriteFileAtomic(file.path, []byte(header+file.sql)); err != nil {
+			service.OutputFatal(err)
+		}
+		service.OutputInfof("Generated %s", file.path)
+	}
+}
+
+// Templates holds the contents of the migration runner templates
+type Templates struct {
+	Runner string // lib/db/migrate/migrate.go
+	Main   string // app/migrate/main.go
+}
+
+// RunOptions configures a run of the project's migration program
+type RunOptions struct {
+	Config string // Config file of the database connection; empty is ./config.yaml
+	Dir    string // Directory of the migration files; empty is <project>/migrations
+	Steps  int    // Number of migrations to apply or revert, 0 for the default
+}
+
+// Run applies (up), reverts (down) or lists (status) the migrations of the
+// project. god has no database drivers, so the work is done by the
+// project's app/migrate program with its own lib/db connector; both are
+// created from tmpls when missing.
+func Run(command string, opts RunOptions, tmpls Templates) {
+	projectRoot, err := service.GetProjectRoot()
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	ensureRunner(projectRoot, tmpls)
+
+	tmpDir, err := os.MkdirTemp("", "god-migrate-*")
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	binary := filepath.Join(tmpDir, "migrate")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	service.CmdDir = projectRoot
+	if out, err := service.RunCommandOutput("go", "build", "-o", binary, "./app/migrate"); err != nil {
+		service.OutputFatal(fmt.Sprintf("Building app/migrate failed: %v\n%s", err, strings.TrimRight(out, "\n")))
+	}
+
+	args := []string{command}
+	for _, flag := range []struct{ name, path string }{{"--config", opts.Config}, {"--dir", opts.Dir}} {
+		if flag.path == "" {
+			continue
+		}
+		// the program runs in the project root
+		path, err := filepath.Abs(flag.path)
+		if err != nil {
+			service.OutputFatal(err)
+		}
+		args = append(args, flag.name, path)
+	}
+	if opts.Steps > 0 {
+		args = append(args, "--steps", fmt.Sprint(opts.Steps))
+	}
+	cmd := exec.Command(binary, args...)
+	cmd.Dir = projectRoot
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// the program has reported the error
+			os.RemoveAll(tmpDir)
+			os.Exit(exitErr.ExitCode())
+		}
+		service.OutputFatal(err)
+	}
+}
+
+// ensureRunner creates lib/db/migrate and app/migrate in projects
+// initialized before they were part of the template
+func ensureRunner(projectRoot string, tmpls Templates) {
+	projectName, err := service.GetProjectName()
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	dialect, err := service.GetDBDialect()
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	if !service.FileExists(filepath.Join(projectRoot, "lib", "db", dialect)) {
+		service.OutputFatal(fmt.Sprintf("lib/db/%s not found: app/migrate connects through the project's %s connector", dialect, dialect))
+	}
+	data := template.ProjectData{ProjectName: projectName, DBDialect: dialect}
+	for _, file := range []struct{ path, content string }{
+		{filepath.Join(projectRoot, "lib", "db", "migrate", "migrate.go"), tmpls.Runner},
+		{filepath.Join(projectRoot, "app", "migrate", "main.go"), tmpls.Main},
+	} {
+		if service.FileExists(file.path) {
+			continue
+		}
+		if err := template.CreateFile(file.content, data, file.path); err != nil {
+			service.OutputFatal(err)
+		}
+		service.OutputInfof("Created %s", file.path)
+	}
+}
+
+// New writes an empty pair of migration files named name
+func New(name, dir string) {
+	if dir == "" {
+		dir = migrationsDir()
+	}
+	base := filepath.Join(dir, newVersion()+"_"+migrationName(name))
+	for _, file := range []struct{ path, sql string }{
+		{base + ".up.sql", "-- Statements applied by god migrate up\n"},
+		{base + ".down.sql", "-- Statements reverting the up migration, run by god migrate down\n"},
+	} {
+		if service.FileExists(file.path) {
+			service.OutputFatal(file.path + " already exists")
+		}
+		if err := template.WriteFileAtomic(file.path, []byte(file.sql)); err != nil {
+			service.OutputFatal(err)
+		}
+		service.OutputInfof("Created %s", file.path)
+	}
+}
+
+// newVersion returns the version of a new migration, the current time
+func newVersion() string {
+	return time.Now().Format("20060102150405")
+}
+
+// loadSchema reads a schema from a SQL file or migrations directory, from a
+// live database, or from the models when src is empty
+func loadSchema(src, dialect string, tables []string) *service.Schema {
+	var schema *service.Schema
+	var err error
+	info, statErr := os.Stat(src)
+	switch {
+	case src == "":
+		projectRoot, err := service.GetProjectRoot()
+		if err != nil {
+			service.OutputFatal(err)
+		}
+		schema, err = service.ParseModels(filepath.Join(projectRoot, "model"), dialect)
+		if err != nil {
+			service.OutputFatal("Error reading models: ", err.Error())
+		}
+	case statErr == nil && (info.IsDir() || strings.HasSuffix(src, ".sql")):
+		if schema, err = service.ParseDDLFile(src, dialect); err != nil {
+			service.OutputFatal("Error parsing SQL: ", err.Error())
+		}
+	case service.DSNDialect(src) != "":
+		if d := service.DSNDialect(src); d != dialect {
+			service.OutputFatal(fmt.Sprintf("%s points to a %s database but the dialect is %s", src, d, dialect))
+		}
+		if schema, err = service.NewIntrospector().Introspect(src, dialect); err != nil {
+			service.OutputFatal("Error reading database schema: ", err.Error())
+		}
+	case statErr == nil:
+		if schema, err = service.ParseDDLFile(src, dialect); err != nil {
+			service.OutputFatal("Error parsing SQL: ", err.Error())
+		}
+	default:
+		service.OutputFatal(statErr)
+	}
+	if err := schema.FilterTables(tables); err != nil {
+		service.OutputFatal(err)
+	}
+	return schema
+}
+
+func describeSource(src string) string {
+	if src == "" {
+		return "models"
+	}
+	return src
+}
+
+// migrationsDir returns the migrations directory of the project
+func migrationsDir() string {
+	projectRoot, err := service.GetProjectRoot()
+	if err != nil {
+		service.OutputFatal(err)
+	}
+	return filepath.Join(projectRoot, "migrations")
+}
+
+var nonNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// migrationName turns a description into the snake_case name of a
+// migration file
+func migrationName(name string) string {
+	name = strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "schema"
+	}
+	return name
+}
@@ -0,0 +1,338 @@
+// Package openapi generates an OpenAPI 3 document from the routes resolved
+// by makerouter and the request/response types referenced in annotations.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/jiajia556/god/internal/cmd/makerouter"
+	"github.com/jiajia556/god/internal/service"
+)
+
+const (
+	openAPIVersion     = "3.0.3"
+	summaryAnnotation  = "@summary"  // Short operation summary
+	tagAnnotation      = "@tag"      // Operation tags, comma-separated
+	requestAnnotation  = "@request"  // Request type, e.g. dto.CreateUserReq
+	responseAnnotation = "@response" // [code] <type|-> [description]
+)
+
+// MakeOpenAPI writes openapi.<format> next to router.go under apiRoot, or to
+// output when it is set. format is "yaml" or "json".
+func MakeOpenAPI(apiRoot, format, output string) {
+	if apiRoot == "" {
+		var err error
+		if apiRoot, err = service.GetDefaultApiRoot(); err != nil {
+			service.OutputFatal("Failed to get API root:", err)
+		}
+	}
+	if format != "json" && format != "yaml" {
+		service.OutputFatal(fmt.Sprintf("unsupported format %q (json or yaml)", format))
+	}
+
+	routes, err := makerouter.Analyze(apiRoot)
+	if err != nil {
+		service.OutputFatal(err)
+	}
+
+	doc, err := buildDocument(routes)
+	if err != nil {
+		service.OutputFatal(err)
+	}
+
+	var content []byte
+	if format == "json" {
+		if content, err = json.MarshalIndent(doc, "", "  "); err != nil {
+			service.OutputFatal(err)
+		}
+		content = append(content, '\n')
+	} else {
+		content = marshalYAML(doc)
+	}
+
+	if output == "" {
+		output = filepath.Join(apiRoot, "openapi."+format)
+	}
+	if err = os.WriteFile(output, content, 0o644); err != nil {
+		service.OutputFatal(err)
+	}
+	service.OutputInfof("OpenAPI spec written to %s", output)
+}
+
+// buildDocument assembles the OpenAPI document for the given routes
+func buildDocument(routes []makerouter.Route) (map[string]any, error) {
+	projectName, err := service.GetProjectName()
+	if err != nil {
+		return nil, err
+	}
+	projectRoot, err := service.GetProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	sb := newSchemaBuilder(projectName, projectRoot)
+	paths := make(map[string]any)
+	files := make(map[string]*ast.File)
+	var ops []routeOperation
+
+	for _, r := range routes {
+		file, ok := files[r.File]
+		if !ok {
+			if file, err = parser.ParseFile(token.NewFileSet(), r.File, nil, parser.ImportsOnly); err != nil {
+				return nil, fmt.Errorf("parse %s: %w", r.File, err)
+			}
+			files[r.File] = file
+		}
+		ctx := typeContext{file: file, dir: filepath.Dir(r.File)}
+
+		openAPIPath := toOpenAPIPath(r.Path)
+		item, _ := paths[openAPIPath].(map[string]any)
+		if item == nil {
+			item = make(map[string]any)
+			paths[openAPIPath] = item
+		}
+		for _, method := range r.HTTPMethods {
+			op, err := sb.operation(r, method, ctx)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", r.File, r.Line, err)
+			}
+			item[strings.ToLower(method)] = op
+			ops = append(ops, routeOperation{op: op, route: r, method: method})
+		}
+	}
+	uniqueOperationIDs(ops)
+
+	doc := map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":   projectName,
+			"version": "1.0.0",
+		},
+		"paths": paths,
+	}
+	if len(sb.components) > 0 {
+		doc["components"] = map[string]any{"schemas": sb.components}
+	}
+	return doc, nil
+}
+
+// routeOperation is an operation object and the route it documents
+type routeOperation struct {
+	op     map[string]any
+	route  makerouter.Route
+	method string
+}
+
+// uniqueOperationIDs qualifies the operationId of operations sharing one,
+// such as the List handlers of user controllers in two packages, with
+// their route path, and numbers any id still taken
+func uniqueOperationIDs(ops []routeOperation) {
+	count := make(map[string]int)
+	for _, o := range ops {
+		count[o.op["operationId"].(string)]++
+	}
+	taken := make(map[string]bool)
+	for _, o := range ops {
+		id := o.op["operationId"].(string)
+		if count[id] > 1 {
+			id = pathOperationID(o.route.Path)
+			if len(o.route.HTTPMethods) > 1 {
+				id += strings.ToUpper(o.method[:1]) + strings.ToLower(o.method[1:])
+			}
+		}
+		for n, base := 2, id; taken[id]; n++ {
+			id = fmt.Sprintf("%s%d", base, n)
+		}
+		taken[id] = true
+		o.op["operationId"] = id
+	}
+}
+
+// pathOperationID turns a route path into a camelCase id, e.g.
+// api/admin/user/list into apiAdminUserList
+func pathOperationID(path string) string {
+	var sb strings.Builder
+	for _, word := range strings.FieldsFunc(path, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if sb.Len() == 0 {
+			sb.WriteString(lowerFirst(word))
+		} else {
+			sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return sb.String()
+}
+
+// operation builds the operation object of a route for a single HTTP method
+func (sb *schemaBuilder) operation(r makerouter.Route, method string, ctx typeContext) (map[string]any, error) {
+	controllerName := strings.TrimSuffix(r.Controller, "Controller")
+	op := map[string]any{
+		"operationId": lowerFirst(controllerName) + r.Handler,
+	}
+	if len(r.HTTPMethods) > 1 {
+		op["operationId"] = lowerFirst(controllerName) + r.Handler + strings.ToUpper(method[:1]) + strings.ToLower(method[1:])
+	}
+
+	var (
+		summary     string
+		description []string
+		tags        []any
+		request     string
+		responses   = make(map[string]any)
+	)
+	for _, line := range r.Doc {
+		switch {
+		case strings.HasPrefix(line, summaryAnnotation):
+			summary = strings.TrimSpace(strings.TrimPrefix(line, summaryAnnotation))
+		case strings.HasPrefix(line, tagAnnotation):
+			for _, tag := range strings.Split(strings.TrimPrefix(line, tagAnnotation), ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					tags = append(tags, tag)
+				}
+			}
+		case strings.HasPrefix(line, requestAnnotation):
+			request = strings.TrimSpace(strings.TrimPrefix(line, requestAnnotation))
+		case strings.HasPrefix(line, responseAnnotation):
+			code, resp, err := sb.response(strings.TrimSpace(strings.TrimPrefix(line, responseAnnotation)), ctx)
+			if err != nil {
+				return nil, err
+			}
+			responses[code] = resp
+		case strings.HasPrefix(line, "@"):
+			// routing annotations are handled by makerouter
+		case line != "":
+			description = append(description, line)
+		}
+	}
+
+	if summary == "" && len(description) > 0 {
+		summary = description[0]
+	}
+	if summary != "" {
+		op["summary"] = summary
+	}
+	if len(description) > 0 {
+		op["description"] = strings.Join(description, "\n")
+	}
+	if len(tags) == 0 {
+		tags = []any{lowerFirst(controllerName)}
+	}
+	op["tags"] = tags
+
+	var params []any
+	for _, seg := range strings.Split(r.Path, "/") {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			params = append(params, map[string]any{
+				"name":     seg[1:],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+	}
+
+	if request != "" {
+		if usesQuery(method) {
+			fields, err := sb.queryFields(request, ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range fields {
+				params = append(params, map[string]any{
+					"name":     f.name,
+					"in":       "query",
+					"required": f.required,
+					"schema":   f.schema,
+				})
+			}
+		} else {
+			schema, err := sb.schemaFor(request, ctx)
+			if err != nil {
+				return nil, err
+			}
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": schema},
+				},
+			}
+		}
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if len(responses) == 0 {
+		responses["200"] = map[string]any{"description": "OK"}
+	}
+	op["responses"] = responses
+	return op, nil
+}
+
+// response parses "[code] <type|-> [description]" into a response object
+func (sb *schemaBuilder) response(spec string, ctx typeContext) (string, map[string]any, error) {
+	fields := strings.Fields(spec)
+	code := "200"
+	if len(fields) > 0 && isStatusCode(fields[0]) {
+		code, fields = fields[0], fields[1:]
+	}
+
+	resp := map[string]any{"description": "OK"}
+	if len(fields) > 1 {
+		resp["description"] = strings.Join(fields[1:], " ")
+	}
+	if len(fields) > 0 && fields[0] != "-" {
+		schema, err := sb.schemaFor(fields[0], ctx)
+		if err != nil {
+			return "", nil, err
+		}
+		resp["content"] = map[string]any{
+			"application/json": map[string]any{"schema": schema},
+		}
+	}
+	return code, resp, nil
+}
+
+// toOpenAPIPath converts gin placeholders (:id, *path) to {id} form
+func toOpenAPIPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// usesQuery reports whether request fields of method are sent as query parameters
+func usesQuery(method string) bool {
+	return method == "GET" || method == "HEAD" || method == "DELETE" || method == "OPTIONS"
+}
+
+func isStatusCode(s string) bool {
+	if len(s) != 3 {
+		return s == "default"
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,39 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/jiajia556/god/internal/cmd/makerouter"
+)
+
+func TestUniqueOperationIDs(t *testing.T) {
+	route := func(path string, methods ...string) makerouter.Route {
+		return makerouter.Route{Path: path, HTTPMethods: methods}
+	}
+	ops := []routeOperation{
+		{op: map[string]any{"operationId": "userList"}, route: route("api/user/list", "POST"), method: "POST"},
+		{op: map[string]any{"operationId": "userList"}, route: route("api/admin/user/list", "POST"), method: "POST"},
+		{op: map[string]any{"operationId": "userDetailGet"}, route: route("api/user/:id", "GET", "HEAD"), method: "GET"},
+		{op: map[string]any{"operationId": "userDetailGet"}, route: route("api/admin/user/:id", "GET", "HEAD"), method: "GET"},
+		{op: map[string]any{"operationId": "apiUserList"}, route: route("api/v2/user/list", "POST"), method: "POST"},
+		{op: map[string]any{"operationId": "orderList"}, route: route("api/order/list", "POST"), method: "POST"},
+	}
+	uniqueOperationIDs(ops)
+
+	want := []string{"apiUserList", "apiAdminUserList", "apiUserIdGet", "apiAdminUserIdGet", "apiUserList2", "orderList"}
+	for i, o := range ops {
+		if got := o.op["operationId"]; got != want[i] {
+			t.Errorf("operation %d: operationId %v, want %s", i, got, want[i])
+		}
+	}
+}
+
+func TestDateTimeSchemaIsNotRFC3339(t *testing.T) {
+	schema := wellKnownSchema("example.com/app/lib/mytime", "DateTime")
+	if _, ok := schema["format"]; ok {
+		t.Errorf("mytime.DateTime schema has format %v, but its values are not RFC 3339", schema["format"])
+	}
+	if schema["pattern"] != datetimePattern {
+		t.Errorf("mytime.DateTime schema pattern = %v, want %s", schema["pattern"], datetimePattern)
+	}
+}
@@ -0,0 +1,443 @@
+package openapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// datetimePattern matches the JSON form of mytime.DateTime: a date or a
+// "2006-01-02 15:04:05" datetime
+const datetimePattern = `^\d{4}-\d{2}-\d{2}( \d{2}:\d{2}:\d{2})?$`
+
+// typeContext is the file and directory a type expression is resolved from
+type typeContext struct {
+	file *ast.File
+	dir  string
+}
+
+// typeDecl is a named type declaration found in a package directory
+type typeDecl struct {
+	spec *ast.TypeSpec
+	ctx  typeContext
+}
+
+// field is a resolved struct field as seen on the wire
+type field struct {
+	name     string
+	schema   map[string]any
+	required bool
+}
+
+// schemaBuilder converts Go types to JSON schemas using go/ast only, so no
+// dependency of the target project has to be downloaded or compiled.
+type schemaBuilder struct {
+	projectName string
+	projectRoot string
+	packages    map[string]map[string]typeDecl // dir -> type name -> declaration
+	components  map[string]any                 // component name -> schema
+	refs        map[string]string              // dir.Type -> component name
+}
+
+func newSchemaBuilder(projectName, projectRoot string) *schemaBuilder {
+	return &schemaBuilder{
+		projectName: projectName,
+		projectRoot: projectRoot,
+		packages:    make(map[string]map[string]typeDecl),
+		components:  make(map[string]any),
+		refs:        make(map[string]string),
+	}
+}
+
+// schemaFor resolves a type expression written in an annotation
+func (sb *schemaBuilder) schemaFor(typeExpr string, ctx typeContext) (map[string]any, error) {
+	expr, err := parser.ParseExpr(typeExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid type %q: %w", typeExpr, err)
+	}
+	return sb.schemaForExpr(expr, ctx)
+}
+
+// queryFields resolves a request type to the fields sent as query parameters
+func (sb *schemaBuilder) queryFields(typeExpr string, ctx typeContext) ([]field, error) {
+	expr, err := parser.ParseExpr(typeExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid type %q: %w", typeExpr, err)
+	}
+	st, stCtx, err := sb.structOf(expr, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		return nil, fmt.Errorf("request type %s is not a struct declared in the project (is its package imported?)", typeExpr)
+	}
+	return sb.structFields(st, stCtx, "form")
+}
+
+func (sb *schemaBuilder) schemaForExpr(expr ast.Expr, ctx typeContext) (map[string]any, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if schema := basicSchema(t.Name); schema != nil {
+			return schema, nil
+		}
+		return sb.named(ctx.dir, t.Name)
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("unsupported type %s", exprString(t))
+		}
+		importPath := resolveImport(ctx.file, pkg.Name)
+		if schema := wellKnownSchema(importPath, t.Sel.Name); schema != nil {
+			return schema, nil
+		}
+		dir, ok := sb.importDir(importPath)
+		if !ok {
+			return map[string]any{"type": "object", "description": "unresolved type " + exprString(t)}, nil
+		}
+		return sb.named(dir, t.Sel.Name)
+	case *ast.StarExpr:
+		return sb.schemaForExpr(t.X, ctx)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return map[string]any{"type": "string", "format": "byte"}, nil
+		}
+		items, err := sb.schemaForExpr(t.Elt, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case *ast.MapType:
+		values, err := sb.schemaForExpr(t.Value, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case *ast.InterfaceType:
+		return map[string]any{}, nil
+	case *ast.StructType:
+		return sb.objectSchema(t, ctx)
+	}
+	return nil, fmt.Errorf("unsupported type %s", exprString(expr))
+}
+
+// named returns a $ref to the component of a struct type, or the inlined
+// schema of any other named type (e.g. type Status string)
+func (sb *schemaBuilder) named(dir, name string) (map[string]any, error) {
+	decl, err := sb.lookup(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	st, ok := decl.spec.Type.(*ast.StructType)
+	if !ok {
+		return sb.schemaForExpr(decl.spec.Type, decl.ctx)
+	}
+
+	key := dir + "." + name
+	if ref, ok := sb.refs[key]; ok {
+		return map[string]any{"$ref": "#/components/schemas/" + ref}, nil
+	}
+
+	component := name
+	for i := 2; sb.components[component] != nil; i++ {
+		component = fmt.Sprintf("%s%d", name, i)
+	}
+	sb.refs[key] = component
+	sb.components[component] = map[string]any{} // placeholder for recursive types
+
+	schema, err := sb.objectSchema(st, decl.ctx)
+	if err != nil {
+		return nil, err
+	}
+	sb.components[component] = schema
+	return map[string]any{"$ref": "#/components/schemas/" + component}, nil
+}
+
+// objectSchema converts a struct to an object schema honoring json tags
+func (sb *schemaBuilder) objectSchema(st *ast.StructType, ctx typeContext) (map[string]any, error) {
+	fields, err := sb.structFields(st, ctx, "json")
+	if err != nil {
+		return nil, err
+	}
+	properties := make(map[string]any)
+	var required []any
+	for _, f := range fields {
+		properties[f.name] = f.schema
+		if f.required {
+			required = append(required, f.name)
+		}
+	}
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// structFields lists the exported fields of a struct, named after tagKey
+// (falling back to the json tag, then the field name). Embedded structs
+// without an explicit name are flattened, as encoding/json does.
+func (sb *schemaBuilder) structFields(st *ast.StructType, ctx typeContext, tagKey string) ([]field, error) {
+	var fields []field
+	for _, f := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if f.Tag != nil {
+			if unquoted, err := strconv.Unquote(f.Tag.Value); err == nil {
+				tag = reflect.StructTag(unquoted)
+			}
+		}
+		name, omit := fieldName(tag, tagKey)
+		if omit {
+			continue
+		}
+		required := strings.Contains(tag.Get("binding"), "required")
+
+		if len(f.Names) == 0 {
+			if name == "" {
+				embedded, embeddedCtx, err := sb.structOf(f.Type, ctx)
+				if err != nil {
+					return nil, err
+				}
+				if embedded != nil {
+					inner, err := sb.structFields(embedded, embeddedCtx, tagKey)
+					if err != nil {
+						return nil, err
+					}
+					fields = append(fields, inner...)
+					continue
+				}
+				name = typeName(f.Type)
+			}
+			if !ast.IsExported(typeName(f.Type)) {
+				continue
+			}
+			schema, err := sb.schemaForExpr(f.Type, ctx)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, field{name: name, schema: schema, required: required})
+			continue
+		}
+
+		for _, ident := range f.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			schema, err := sb.schemaForExpr(f.Type, ctx)
+			if err != nil {
+				return nil, err
+			}
+			fieldName := name
+			if fieldName == "" {
+				fieldName = ident.Name
+			}
+			fields = append(fields, field{name: fieldName, schema: schema, required: required})
+		}
+	}
+	return fields, nil
+}
+
+// structOf resolves expr to a struct type declared in the project, or nil
+func (sb *schemaBuilder) structOf(expr ast.Expr, ctx typeContext) (*ast.StructType, typeContext, error) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return sb.structOf(t.X, ctx)
+	case *ast.StructType:
+		return t, ctx, nil
+	case *ast.Ident:
+		if basicSchema(t.Name) != nil {
+			return nil, ctx, nil
+		}
+		decl, err := sb.lookup(ctx.dir, t.Name)
+		if err != nil {
+			return nil, ctx, err
+		}
+		return sb.structOf(decl.spec.Type, decl.ctx)
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return nil, ctx, nil
+		}
+		importPath := resolveImport(ctx.file, pkg.Name)
+		if wellKnownSchema(importPath, t.Sel.Name) != nil {
+			return nil, ctx, nil
+		}
+		dir, ok := sb.importDir(importPath)
+		if !ok {
+			return nil, ctx, nil
+		}
+		decl, err := sb.lookup(dir, t.Sel.Name)
+		if err != nil {
+			return nil, ctx, err
+		}
+		return sb.structOf(decl.spec.Type, decl.ctx)
+	}
+	return nil, ctx, nil
+}
+
+// lookup finds a type declaration in the package located at dir
+func (sb *schemaBuilder) lookup(dir, name string) (typeDecl, error) {
+	types, ok := sb.packages[dir]
+	if !ok {
+		var err error
+		if types, err = parsePackageTypes(dir); err != nil {
+			return typeDecl{}, err
+		}
+		sb.packages[dir] = types
+	}
+	decl, ok := types[name]
+	if !ok {
+		return typeDecl{}, fmt.Errorf("type %s not found in %s", name, dir)
+	}
+	return decl, nil
+}
+
+// importDir maps a project import path to its directory on disk
+func (sb *schemaBuilder) importDir(importPath string) (string, bool) {
+	if importPath == sb.projectName {
+		return sb.projectRoot, true
+	}
+	rel, ok := strings.CutPrefix(importPath, sb.projectName+"/")
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(sb.projectRoot, filepath.FromSlash(rel)), true
+}
+
+// parsePackageTypes collects the type declarations of all non-test Go files in dir
+func parsePackageTypes(dir string) (map[string]typeDecl, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]typeDecl)
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					types[ts.Name.Name] = typeDecl{spec: ts, ctx: typeContext{file: file, dir: dir}}
+				}
+			}
+		}
+	}
+	return types, nil
+}
+
+// resolveImport returns the import path bound to a package name in file
+func resolveImport(file *ast.File, pkgName string) string {
+	for _, imp := range file.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		if imp.Name != nil && imp.Name.Name != "_" {
+			if imp.Name.Name == pkgName {
+				return p
+			}
+			continue
+		}
+		if p == pkgName || strings.HasSuffix(p, "/"+pkgName) {
+			return p
+		}
+	}
+	return pkgName
+}
+
+// fieldName reads the wire name of a field from tagKey, then json.
+// omit is true for fields tagged "-".
+func fieldName(tag reflect.StructTag, tagKey string) (name string, omit bool) {
+	for _, key := range []string{tagKey, "json"} {
+		value, ok := tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		name = strings.Split(value, ",")[0]
+		if name == "-" {
+			return "", true
+		}
+		if name != "" {
+			return name, false
+		}
+	}
+	return "", false
+}
+
+// basicSchema maps predeclared Go types to JSON schemas
+func basicSchema(name string) map[string]any {
+	switch name {
+	case "string":
+		return map[string]any{"type": "string"}
+	case "bool":
+		return map[string]any{"type": "boolean"}
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32", "byte", "rune":
+		return map[string]any{"type": "integer", "format": "int32"}
+	case "int64", "uint64", "uintptr":
+		return map[string]any{"type": "integer", "format": "int64"}
+	case "float32":
+		return map[string]any{"type": "number", "format": "float"}
+	case "float64":
+		return map[string]any{"type": "number", "format": "double"}
+	case "any", "error":
+		return map[string]any{}
+	}
+	return nil
+}
+
+// wellKnownSchema maps common library types to JSON schemas
+func wellKnownSchema(importPath, name string) map[string]any {
+	switch {
+	case importPath == "time" && name == "Time":
+		return map[string]any{"type": "string", "format": "date-time"}
+	case strings.HasSuffix(importPath, "/lib/mytime") && name == "DateTime":
+		// not RFC 3339, so no date-time format
+		return map[string]any{"type": "string", "pattern": datetimePattern, "example": "2006-01-02 15:04:05"}
+	case strings.HasSuffix(importPath, "/decimal") && name == "Decimal":
+		return map[string]any{"type": "string", "format": "decimal"}
+	case importPath == "github.com/gin-gonic/gin" && name == "H":
+		return map[string]any{"type": "object"}
+	case importPath == "encoding/json" && name == "RawMessage":
+		return map[string]any{}
+	}
+	return nil
+}
+
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(t.Elt)
+	}
+	return fmt.Sprintf("%T", expr)
+}
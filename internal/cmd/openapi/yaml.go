@@ -0,0 +1,92 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// plainKey matches mapping keys that need no quoting in YAML
+var plainKey = regexp.MustCompile(`^[A-Za-z_$/][A-Za-z0-9_$./{}-]*$`)
+
+// marshalYAML encodes the document built by buildDocument as block-style
+// YAML. Only the value kinds used by the document are supported; map keys
+// are sorted so output is stable.
+func marshalYAML(v any) []byte {
+	var b bytes.Buffer
+	writeYAML(&b, v, 0)
+	return b.Bytes()
+}
+
+func writeYAML(b *bytes.Buffer, v any, indent int) {
+	pad := strings.Repeat(" ", indent)
+	switch t := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			b.WriteString(pad + yamlKey(k) + ":")
+			writeYAMLValue(b, t[k], indent)
+		}
+	case []any:
+		for _, item := range t {
+			if m, ok := item.(map[string]any); ok && len(m) > 0 {
+				// "- key: value" with the remaining keys aligned under the first
+				var inner bytes.Buffer
+				writeYAML(&inner, m, indent+2)
+				b.WriteString(pad + "- " + strings.TrimPrefix(inner.String(), pad+"  "))
+				continue
+			}
+			b.WriteString(pad + "-")
+			writeYAMLValue(b, item, indent)
+		}
+	}
+}
+
+// writeYAMLValue writes a value following a "key:" or "-" indicator
+func writeYAMLValue(b *bytes.Buffer, v any, indent int) {
+	switch t := v.(type) {
+	case map[string]any:
+		if len(t) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAML(b, t, indent+2)
+	case []any:
+		if len(t) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAML(b, t, indent+2)
+	default:
+		b.WriteString(" " + yamlScalar(t) + "\n")
+	}
+}
+
+func yamlKey(k string) string {
+	if plainKey.MatchString(k) && !strings.HasPrefix(k, "{") {
+		return k
+	}
+	return strconv.Quote(k)
+}
+
+func yamlScalar(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(t)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		return fmt.Sprint(t)
+	}
+}
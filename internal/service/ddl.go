@@ -0,0 +1,1064 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Schema is the set of tables declared by DDL statements
+type Schema struct {
+	Tables []*Table
+	Enums  map[string][]string // Values of Postgres CREATE TYPE ... AS ENUM types by lower-cased name
+}
+
+// Table is a table declared by a CREATE TABLE statement
+type Table struct {
+	Schema      string // Schema qualifier, e.g. public in public.users
+	Name        string
+	Columns     []*Column
+	PrimaryKey  []string // Columns of a table-level PRIMARY KEY
+	Indexes     []*Index // Table-level keys and CREATE INDEX statements
+	ForeignKeys []*ForeignKey
+	Comment     string
+	Line        int // Position of the CREATE keyword
+	Col         int
+}
+
+// Index is a UNIQUE, KEY/INDEX, FULLTEXT or SPATIAL index
+type Index struct {
+	Name    string // Empty when the DDL does not name the index
+	Columns []string
+	Unique  bool
+	Class   string // FULLTEXT or SPATIAL
+	Method  string // Lower-cased USING method, e.g. btree or gin
+}
+
+// ForeignKey is a table-level FOREIGN KEY constraint
+type ForeignKey struct {
+	Name    string
+	Columns []string
+	Reference
+}
+
+// Column is a column definition of a CREATE TABLE statement
+type Column struct {
+	Name          string
+	Type          string // Lower-cased declared type with modifiers, e.g. varchar(64), int unsigned, text[]
+	NotNull       bool
+	PrimaryKey    bool
+	AutoIncrement bool // AUTO_INCREMENT, AUTOINCREMENT or an identity column
+	Unique        bool
+	HasDefault    bool
+	Default       string // Literal value, or the lower-cased expression such as now()
+	DefaultQuoted bool   // Default is the value of a string literal
+	OnUpdate      string // Lower-cased MySQL ON UPDATE expression, e.g. current_timestamp
+	Comment       string
+	Generated     string     // Expression of a GENERATED ALWAYS AS column with its storage, e.g. (price*qty) stored
+	Values        []string   // Members of an ENUM or SET type, or of a Postgres enum type
+	CheckValues   bool       // Values are enforced by CHECK (name IN (...)) rather than the type
+	References    *Reference // Inline REFERENCES clause
+	ReferenceName string     // CONSTRAINT name of the inline REFERENCES clause
+	Line          int
+	Col           int
+}
+
+// Reference is the target of a foreign key
+type Reference struct {
+	Table    string
+	Columns  []string
+	OnDelete string // Upper-cased referential action, e.g. CASCADE
+	OnUpdate string
+}
+
+// ParseDDLFile reads and parses the DDL file at path, see ParseDDL. When
+// path is a directory its migrations are replayed, see ParseDDLDir.
+func ParseDDLFile(path, dialect string) (*Schema, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return ParseDDLDir(path, dialect)
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDDL(path, string(src), dialect)
+}
+
+// ParseDDLDir replays the .sql files of dir in filename order into a
+// single schema, so that a base CREATE TABLE file followed by incremental
+// migrations yields the final state. Down migrations (*.down.sql) and
+// subdirectories are skipped.
+func ParseDDLDir(dir, dialect string) (*Schema, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	schema := &Schema{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".sql") || strings.HasSuffix(name, ".down.sql") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := schema.apply(path, string(src), dialect); err != nil {
+			return nil, err
+		}
+	}
+	schema.resolveEnums()
+	return schema, nil
+}
+
+// ParseDDL parses the DDL statements of src written in dialect. CREATE
+// TABLE, CREATE INDEX, ALTER TABLE, RENAME TABLE and DROP TABLE/INDEX
+// statements are applied in order; other statements are skipped.
+// Statements may span or share lines and keywords are case-insensitive.
+// Syntax errors are reported as *DDLError with the file, line and column
+// of the problem.
+func ParseDDL(file, src, dialect string) (*Schema, error) {
+	schema := &Schema{}
+	if err := schema.apply(file, src, dialect); err != nil {
+		return nil, err
+	}
+	schema.resolveEnums()
+	return schema, nil
+}
+
+// apply parses the statements of src and applies them to the schema
+func (s *Schema) apply(file, src, dialect string) error {
+	toks, err := lexDDL(file, dialect, src)
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range splitStatements(toks) {
+		p := &ddlParser{file: file, dialect: dialect, toks: stmt}
+		var err error
+		switch {
+		case p.isCreate("INDEX"):
+			err = p.parseCreateIndex(s)
+		case p.acceptKeywords("COMMENT", "ON"):
+			err = p.parseCommentOn(s)
+		case p.isCreate("TYPE"):
+			err = p.parseCreateType(s)
+		case p.isCreate("TABLE"):
+			err = p.parseCreateTableInto(s)
+		case isKeyword(p.peek(), "ALTER") && p.isAlterTable():
+			err = p.parseAlterTable(s)
+		case p.acceptKeywords("RENAME", "TABLE"):
+			err = p.parseRenameTable(s)
+		case isKeyword(p.peek(), "DROP"):
+			err = p.parseDrop(s)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveEnums sets the values of columns typed with a Postgres enum type
+func (s *Schema) resolveEnums() {
+	for _, table := range s.Tables {
+		for _, col := range table.Columns {
+			if values, ok := s.Enums[col.Type]; ok && col.Values == nil {
+				col.Values = values
+			}
+		}
+	}
+}
+
+// splitStatements splits tokens on top-level semicolons. Each statement
+// ends with a tokEOF token positioned at its terminator.
+func splitStatements(toks []ddlToken) [][]ddlToken {
+	var (
+		stmts [][]ddlToken
+		cur   []ddlToken
+		depth int
+	)
+	for _, t := range toks {
+		switch {
+		case t.kind == tokPunct && t.text == "(":
+			depth++
+		case t.kind == tokPunct && t.text == ")" && depth > 0:
+			depth--
+		case t.kind == tokEOF || (t.kind == tokPunct && t.text == ";" && depth == 0):
+			if len(cur) > 0 {
+				stmts = append(stmts, append(cur, ddlToken{kind: tokEOF, line: t.line, col: t.col}))
+			}
+			cur, depth = nil, 0
+			continue
+		}
+		cur = append(cur, t)
+	}
+	return stmts
+}
+
+// tableConstraintKeywords start definitions in a column list that are not columns
+var tableConstraintKeywords = []string{"CONSTRAINT", "PRIMARY", "UNIQUE", "KEY", "INDEX", "FOREIGN", "CHECK",
+	"FULLTEXT", "SPATIAL", "EXCLUDE", "LIKE", "PERIOD"}
+
+// columnConstraintKeywords end the type of a column definition
+var columnConstraintKeywords = []string{"CONSTRAINT", "NOT", "NULL", "PRIMARY", "KEY", "UNIQUE", "DEFAULT",
+	"AUTO_INCREMENT", "AUTOINCREMENT", "COMMENT", "CHECK", "REFERENCES", "COLLATE", "CHARSET", "GENERATED",
+	"AS", "ON", "STORED", "VIRTUAL", "VISIBLE", "INVISIBLE", "COLUMN_FORMAT", "STORAGE", "IDENTITY",
+	"FIRST", "AFTER"}
+
+// ddlParser parses a single statement
+type ddlParser struct {
+	file    string
+	dialect string
+	toks    []ddlToken
+	pos     int
+}
+
+func (p *ddlParser) peek() ddlToken {
+	return p.toks[p.pos]
+}
+
+func (p *ddlParser) peekAt(i int) ddlToken {
+	if p.pos+i < len(p.toks) {
+		return p.toks[p.pos+i]
+	}
+	return p.toks[len(p.toks)-1]
+}
+
+func (p *ddlParser) next() ddlToken {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *ddlParser) errorf(t ddlToken, format string, args ...any) error {
+	return &DDLError{File: p.file, Line: t.line, Column: t.col, Message: fmt.Sprintf(format, args...)}
+}
+
+// isKeyword reports whether t is the bare word kw, case-insensitively
+func isKeyword(t ddlToken, kws ...string) bool {
+	if t.kind != tokIdent {
+		return false
+	}
+	for _, kw := range kws {
+		if strings.EqualFold(t.text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPunct(t ddlToken, s string) bool {
+	return t.kind == tokPunct && t.text == s
+}
+
+// acceptKeywords consumes the keyword sequence kws if it comes next
+func (p *ddlParser) acceptKeywords(kws ...string) bool {
+	for i, kw := range kws {
+		if !isKeyword(p.peekAt(i), kw) {
+			return false
+		}
+	}
+	p.pos += len(kws)
+	return true
+}
+
+func (p *ddlParser) acceptPunct(s string) bool {
+	if isPunct(p.peek(), s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *ddlParser) expectPunct(s string) error {
+	if !p.acceptPunct(s) {
+		return p.errorf(p.peek(), "expected %q, got %s", s, describe(p.peek()))
+	}
+	return nil
+}
+
+// name consumes an identifier: a bare word, a quoted identifier, or in
+// MySQL a double-quoted string
+func (p *ddlParser) name(what string) (string, error) {
+	t := p.peek()
+	if t.kind == tokIdent || t.kind == tokQuoted || (t.kind == tokString && p.dialect == DialectMySQL) {
+		p.pos++
+		return t.text, nil
+	}
+	return "", p.errorf(t, "expected %s, got %s", what, describe(t))
+}
+
+// qualifiedName consumes name or schema.name
+func (p *ddlParser) qualifiedName(what string) (string, string, error) {
+	first, err := p.name(what)
+	if err != nil {
+		return "", "", err
+	}
+	if !p.acceptPunct(".") {
+		return "", first, nil
+	}
+	second, err := p.name(what)
+	return first, second, err
+}
+
+// Lookup returns the table named name, ignoring case, or nil
+func (s *Schema) Lookup(name string) *Table {
+	for _, t := range s.Tables {
+		if strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// isCreate reports whether the statement is CREATE [modifiers] object,
+// where object is TABLE, INDEX or TYPE
+func (p *ddlParser) isCreate(object string) bool {
+	if !isKeyword(p.peek(), "CREATE") {
+		return false
+	}
+	for i := 1; i < len(p.toks); i++ {
+		t := p.toks[i]
+		switch {
+		case isKeyword(t, object):
+			return true
+		case !isKeyword(t, "OR", "REPLACE", "GLOBAL", "LOCAL", "TEMP", "TEMPORARY", "UNLOGGED", "VIRTUAL",
+			"UNIQUE", "FULLTEXT", "SPATIAL"):
+			return false
+		}
+	}
+	return false
+}
+
+// parseCreateTableInto adds the table of a CREATE TABLE statement to the
+// schema. A table of the same name is replaced, unless the statement says
+// IF NOT EXISTS.
+func (p *ddlParser) parseCreateTableInto(schema *Schema) error {
+	ifNotExists := false
+	for _, t := range p.toks {
+		if isPunct(t, "(") {
+			break
+		}
+		ifNotExists = ifNotExists || isKeyword(t, "EXISTS")
+	}
+	table, err := p.parseCreateTable(schema)
+	if err != nil || table == nil {
+		return err
+	}
+	for i, t := range schema.Tables {
+		if strings.EqualFold(t.Name, table.Name) {
+			if !ifNotExists {
+				schema.Tables[i] = table
+			}
+			return nil
+		}
+	}
+	schema.Tables = append(schema.Tables, table)
+	return nil
+}
+
+// parseCreateTable parses a CREATE TABLE statement, copying the tables of
+// LIKE clauses from schema. Statements without a column list (CREATE
+// TABLE ... AS SELECT, PARTITION OF) yield nil.
+func (p *ddlParser) parseCreateTable(schema *Schema) (*Table, error) {
+	create := p.peek()
+	for !isKeyword(p.next(), "TABLE") {
+		// CREATE and modifiers such as TEMPORARY
+	}
+	p.acceptKeywords("IF", "NOT", "EXISTS")
+
+	table := &Table{Line: create.line, Col: create.col}
+	var err error
+	if table.Schema, table.Name, err = p.qualifiedName("table name"); err != nil {
+		return nil, err
+	}
+	if p.acceptKeywords("LIKE") {
+		return table, p.parseLike(schema, table)
+	}
+	open := p.peek()
+	if !p.acceptPunct("(") {
+		return nil, nil
+	}
+
+	for {
+		t := p.peek()
+		switch {
+		case p.acceptKeywords("LIKE"):
+			if err := p.parseLike(schema, table); err != nil {
+				return nil, err
+			}
+		case isKeyword(t, tableConstraintKeywords...):
+			if err := p.parseTableConstraint(table); err != nil {
+				return nil, err
+			}
+		default:
+			col, err := p.parseColumn()
+			if err != nil {
+				return nil, err
+			}
+			table.Columns = append(table.Columns, col)
+		}
+
+		if p.acceptPunct(")") {
+			break
+		}
+		if p.peek().kind == tokEOF {
+			return nil, p.errorf(open, "unclosed \"(\" of table %s", table.Name)
+		}
+		if err := p.expectPunct(","); err != nil {
+			return nil, err
+		}
+	}
+
+	// table options, e.g. ENGINE=InnoDB COMMENT='users'
+	for p.peek().kind != tokEOF {
+		if p.acceptKeywords("COMMENT") {
+			p.acceptPunct("=")
+			if p.peek().kind != tokString {
+				return nil, p.errorf(p.peek(), "expected comment string, got %s", describe(p.peek()))
+			}
+			table.Comment = p.next().text
+			continue
+		}
+		p.skipToken()
+	}
+	return table, nil
+}
+
+// likeParts are the parts of a table that a Postgres LIKE clause copies
+// when they are INCLUDED
+var likeParts = []string{"COMMENTS", "CONSTRAINTS", "DEFAULTS", "GENERATED", "IDENTITY", "INDEXES"}
+
+// parseLike parses "LIKE table [{INCLUDING | EXCLUDING} part ...]" and adds
+// the columns of table to the new table. MySQL copies the whole table
+// except its foreign keys, Postgres only columns, types and NOT NULL unless
+// more parts are included.
+func (p *ddlParser) parseLike(schema *Schema, table *Table) error {
+	start := p.peek()
+	_, name, err := p.qualifiedName("table name")
+	if err != nil {
+		return err
+	}
+	src := schema.Lookup(name)
+	if src == nil {
+		return p.errorf(start, "table %s of LIKE is not defined", name)
+	}
+	copied := map[string]bool{}
+	for _, part := range likeParts {
+		copied[part] = p.dialect == DialectMySQL
+	}
+	for {
+		including := p.acceptKeywords("INCLUDING")
+		if !including && !p.acceptKeywords("EXCLUDING") {
+			break
+		}
+		part := strings.ToUpper(p.next().text)
+		for _, k := range likeParts {
+			if part == "ALL" || part == k {
+				copied[k] = including
+			}
+		}
+	}
+
+	for _, c := range src.Columns {
+		col := &Column{Name: c.Name, Type: c.Type, NotNull: c.NotNull || c.PrimaryKey, Values: c.Values,
+			Line: start.line, Col: start.col}
+		if copied["DEFAULTS"] {
+			col.HasDefault, col.Default, col.DefaultQuoted, col.OnUpdate = c.HasDefault, c.Default, c.DefaultQuoted, c.OnUpdate
+		}
+		if copied["IDENTITY"] {
+			col.AutoIncrement = c.AutoIncrement
+		}
+		if copied["GENERATED"] {
+			col.Generated = c.Generated
+		}
+		if copied["INDEXES"] {
+			col.PrimaryKey, col.Unique = c.PrimaryKey, c.Unique
+		}
+		if copied["COMMENTS"] {
+			col.Comment = c.Comment
+		}
+		if copied["CONSTRAINTS"] {
+			col.CheckValues = c.CheckValues
+		}
+		table.Columns = append(table.Columns, col)
+	}
+	if copied["INDEXES"] {
+		table.PrimaryKey = slices.Clone(src.PrimaryKey)
+		for _, idx := range src.Indexes {
+			dup := *idx
+			dup.Columns = slices.Clone(idx.Columns)
+			if p.dialect != DialectMySQL {
+				// index names are unique per schema, the copies get new ones
+				dup.Name = ""
+			}
+			table.Indexes = append(table.Indexes, &dup)
+		}
+	}
+	if p.dialect == DialectMySQL {
+		table.Comment = src.Comment
+	}
+	return nil
+}
+
+// parseTableConstraint parses a table-level definition of the column list:
+// PRIMARY KEY, UNIQUE, KEY/INDEX, FULLTEXT/SPATIAL and FOREIGN KEY clauses
+// are recorded on table, CHECK and other clauses are skipped
+func (p *ddlParser) parseTableConstraint(table *Table) error {
+	name := ""
+	if p.acceptKeywords("CONSTRAINT") && !isKeyword(p.peek(), "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "EXCLUDE") {
+		var err error
+		if name, err = p.name("constraint name"); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case p.acceptKeywords("PRIMARY", "KEY"):
+		idx := &Index{}
+		ok, err := p.parseIndexBody(idx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			table.PrimaryKey = idx.Columns
+		}
+	case isKeyword(p.peek(), "UNIQUE", "KEY", "INDEX", "FULLTEXT", "SPATIAL"):
+		idx := &Index{Name: name}
+		switch kw := strings.ToUpper(p.next().text); kw {
+		case "UNIQUE":
+			idx.Unique = true
+		case "FULLTEXT", "SPATIAL":
+			idx.Class = kw
+		}
+		if idx.Unique || idx.Class != "" {
+			_ = p.acceptKeywords("KEY") || p.acceptKeywords("INDEX")
+		}
+		ok, err := p.parseIndexBody(idx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			table.Indexes = append(table.Indexes, idx)
+		}
+	case p.acceptKeywords("FOREIGN", "KEY"):
+		fk := &ForeignKey{Name: name}
+		if !isPunct(p.peek(), "(") {
+			// MySQL allows naming the generated index here
+			if _, err := p.name("index name"); err != nil {
+				return err
+			}
+		}
+		if err := p.expectPunct("("); err != nil {
+			return err
+		}
+		var err error
+		if fk.Columns, err = p.nameList(); err != nil {
+			return err
+		}
+		if !p.acceptKeywords("REFERENCES") {
+			return p.errorf(p.peek(), "expected REFERENCES, got %s", describe(p.peek()))
+		}
+		ref, err := p.parseReference()
+		if err != nil {
+			return err
+		}
+		fk.Reference = *ref
+		table.ForeignKeys = append(table.ForeignKeys, fk)
+	}
+	p.skipDefinition()
+	return nil
+}
+
+// parseIndexBody parses "[name] [USING method] (columns)" into idx. ok is
+// false for indexes on expressions, which cannot be mapped to fields.
+func (p *ddlParser) parseIndexBody(idx *Index) (ok bool, err error) {
+	if !isPunct(p.peek(), "(") && !isKeyword(p.peek(), "USING") {
+		if idx.Name, err = p.name("index name"); err != nil {
+			return false, err
+		}
+	}
+	if p.acceptKeywords("USING") {
+		idx.Method = strings.ToLower(p.next().text)
+	}
+	open := p.peek()
+	if err := p.expectPunct("("); err != nil {
+		return false, err
+	}
+
+	ok = true
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokIdent || t.kind == tokQuoted:
+			p.next()
+			idx.Columns = append(idx.Columns, t.text)
+			// a prefix length such as name(10) is allowed, a call such as lower(name) is not
+			if isPunct(p.peek(), "(") && !(p.peekAt(1).kind == tokNumber && isPunct(p.peekAt(2), ")")) {
+				ok = false
+			}
+		default:
+			ok = false
+		}
+		// skip prefix lengths, ASC/DESC, COLLATE, operator classes...
+		for !isPunct(p.peek(), ",") && !isPunct(p.peek(), ")") && p.peek().kind != tokEOF {
+			p.skipToken()
+		}
+		if p.acceptPunct(")") {
+			return ok, nil
+		}
+		if p.peek().kind == tokEOF {
+			return false, p.errorf(open, "unclosed \"(\" of index column list")
+		}
+		p.next()
+	}
+}
+
+// parseCreateIndex parses a CREATE INDEX statement and records the index
+// on its table. Indexes of tables not declared earlier in the file and
+// partial (WHERE) indexes are ignored.
+func (p *ddlParser) parseCreateIndex(schema *Schema) error {
+	idx := &Index{}
+	p.next()
+	for t := p.next(); !isKeyword(t, "INDEX"); t = p.next() {
+		switch {
+		case isKeyword(t, "UNIQUE"):
+			idx.Unique = true
+		case isKeyword(t, "FULLTEXT", "SPATIAL"):
+			idx.Class = strings.ToUpper(t.text)
+		}
+	}
+	p.acceptKeywords("CONCURRENTLY")
+	p.acceptKeywords("IF", "NOT", "EXISTS")
+	if !isKeyword(p.peek(), "ON") {
+		var err error
+		if _, idx.Name, err = p.qualifiedName("index name"); err != nil {
+			return err
+		}
+	}
+	if p.acceptKeywords("USING") {
+		idx.Method = strings.ToLower(p.next().text)
+	}
+	if !p.acceptKeywords("ON") {
+		return p.errorf(p.peek(), "expected ON, got %s", describe(p.peek()))
+	}
+	p.acceptKeywords("ONLY")
+	_, tableName, err := p.qualifiedName("table name")
+	if err != nil {
+		return err
+	}
+	ok, err := p.parseIndexBody(idx)
+	if err != nil {
+		return err
+	}
+	for p.peek().kind != tokEOF {
+		if isKeyword(p.peek(), "WHERE") {
+			ok = false
+		}
+		p.skipToken()
+	}
+	if table := schema.Lookup(tableName); table != nil && ok {
+		table.Indexes = append(table.Indexes, idx)
+	}
+	return nil
+}
+
+// parseCommentOn parses the rest of a Postgres COMMENT ON TABLE or COMMENT
+// ON COLUMN statement and sets the comment of a table declared earlier in
+// the file. Comments on other objects are ignored.
+func (p *ddlParser) parseCommentOn(schema *Schema) error {
+	column := p.acceptKeywords("COLUMN")
+	if !column && !p.acceptKeywords("TABLE") {
+		return nil
+	}
+	// [schema.]table or [schema.]table.column
+	var names []string
+	for {
+		n, err := p.name("object name")
+		if err != nil {
+			return err
+		}
+		names = append(names, n)
+		if !p.acceptPunct(".") {
+			break
+		}
+	}
+	if !p.acceptKeywords("IS") {
+		return p.errorf(p.peek(), "expected IS, got %s", describe(p.peek()))
+	}
+	comment := ""
+	if t := p.next(); t.kind == tokString {
+		comment = t.text
+	} else if !isKeyword(t, "NULL") {
+		return p.errorf(t, "expected comment string, got %s", describe(t))
+	}
+
+	if !column {
+		if table := schema.Lookup(names[len(names)-1]); table != nil {
+			table.Comment = comment
+		}
+		return nil
+	}
+	if len(names) < 2 {
+		return nil
+	}
+	if table := schema.Lookup(names[len(names)-2]); table != nil {
+		for _, col := range table.Columns {
+			if strings.EqualFold(col.Name, names[len(names)-1]) {
+				col.Comment = comment
+			}
+		}
+	}
+	return nil
+}
+
+// parseCreateType records the values of a Postgres CREATE TYPE name AS
+// ENUM (...) statement. Other types are ignored.
+func (p *ddlParser) parseCreateType(schema *Schema) error {
+	p.acceptKeywords("CREATE")
+	p.acceptKeywords("TYPE")
+	schemaName, name, err := p.qualifiedName("type name")
+	if err != nil {
+		return err
+	}
+	if !p.acceptKeywords("AS", "ENUM") {
+		return nil
+	}
+	if err := p.expectPunct("("); err != nil {
+		return err
+	}
+	var values []string
+	for !p.acceptPunct(")") {
+		t := p.next()
+		switch {
+		case t.kind == tokString:
+			values = append(values, t.text)
+		case isPunct(t, ","):
+		default:
+			return p.errorf(t, "expected enum value, got %s", describe(t))
+		}
+	}
+	if schema.Enums == nil {
+		schema.Enums = make(map[string][]string)
+	}
+	name = strings.ToLower(name)
+	schema.Enums[name] = values
+	if schemaName != "" {
+		schema.Enums[strings.ToLower(schemaName)+"."+name] = values
+	}
+	return nil
+}
+
+// parseColumn parses "name type [constraints...]" up to the next
+// top-level comma or closing parenthesis
+func (p *ddlParser) parseColumn() (*Column, error) {
+	start := p.peek()
+	name, err := p.name("column name")
+	if err != nil {
+		return nil, err
+	}
+	col := &Column{Name: name, Line: start.line, Col: start.col}
+
+	// the declared type runs up to the first constraint keyword
+	typeStart := p.pos
+	for !p.atDefinitionEnd() {
+		t := p.peek()
+		if isKeyword(t, columnConstraintKeywords...) ||
+			(isKeyword(t, "CHARACTER") && isKeyword(p.peekAt(1), "SET")) {
+			break
+		}
+		p.skipToken()
+	}
+	col.Type = renderTokens(p.toks[typeStart:p.pos], true)
+	col.Values = typeValues(p.toks[typeStart:p.pos])
+
+	// FIRST and AFTER end a column definition of ALTER TABLE, see positionColumn
+	constraint := ""
+	for !p.atDefinitionEnd() && !isKeyword(p.peek(), "FIRST", "AFTER") {
+		switch {
+		case p.acceptKeywords("CONSTRAINT"):
+			if constraint, err = p.name("constraint name"); err != nil {
+				return nil, err
+			}
+			continue
+		case p.acceptKeywords("NOT", "NULL"):
+			col.NotNull = true
+		case p.acceptKeywords("NULL"):
+		case p.acceptKeywords("PRIMARY", "KEY"), p.acceptKeywords("KEY"):
+			col.PrimaryKey = true
+		case p.acceptKeywords("UNIQUE"):
+			p.acceptKeywords("KEY")
+			col.Unique = true
+		case p.acceptKeywords("DEFAULT"):
+			value, literal, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			col.HasDefault, col.Default, col.DefaultQuoted = true, value, literal
+		case p.acceptKeywords("AUTO_INCREMENT"), p.acceptKeywords("AUTOINCREMENT"):
+			col.AutoIncrement = true
+		case p.acceptKeywords("GENERATED", "ALWAYS", "AS", "IDENTITY"),
+			p.acceptKeywords("GENERATED", "BY", "DEFAULT", "AS", "IDENTITY"):
+			col.AutoIncrement = true
+		case p.acceptKeywords("GENERATED", "ALWAYS", "AS"), p.acceptKeywords("AS"):
+			if col.Generated, err = p.parseGenerated(); err != nil {
+				return nil, err
+			}
+		case p.acceptKeywords("COMMENT"):
+			if p.peek().kind != tokString {
+				return nil, p.errorf(p.peek(), "expected comment string, got %s", describe(p.peek()))
+			}
+			col.Comment = p.next().text
+		case p.acceptKeywords("REFERENCES"):
+			if col.References, err = p.parseReference(); err != nil {
+				return nil, err
+			}
+			col.ReferenceName = constraint
+		case p.acceptKeywords("ON", "UPDATE"):
+			if col.OnUpdate, _, err = p.parseExpr(); err != nil {
+				return nil, err
+			}
+		case p.acceptKeywords("COLLATE"), p.acceptKeywords("CHARACTER", "SET"), p.acceptKeywords("CHARSET"):
+			p.skipToken()
+		default:
+			p.skipToken()
+		}
+		// a CONSTRAINT name only applies to the clause right after it
+		constraint = ""
+	}
+	return col, nil
+}
+
+// parseGenerated parses the "(expr) [VIRTUAL | STORED]" of a generated
+// column, lower-casing words as parseExpr does
+func (p *ddlParser) parseGenerated() (string, error) {
+	if !isPunct(p.peek(), "(") {
+		return "", p.errorf(p.peek(), "expected \"(\", got %s", describe(p.peek()))
+	}
+	start := p.pos
+	p.skipToken()
+	expr := slices.Clone(p.toks[start:p.pos])
+	if p.dialect == DialectMySQL {
+		// renderTokens writes quoted names in double quotes, which MySQL
+		// reads as strings; its column names ignore case
+		for i, t := range expr {
+			if t.kind == tokQuoted {
+				expr[i] = ddlToken{kind: tokIdent, text: QuoteIdent(t.text, DialectMySQL)}
+			}
+		}
+	}
+	generated := renderTokens(expr, true)
+	switch {
+	case p.acceptKeywords("STORED"):
+		generated += " stored"
+	case p.acceptKeywords("VIRTUAL"):
+		generated += " virtual"
+	}
+	return generated, nil
+}
+
+// parseReference parses the target of REFERENCES: table [(columns)]
+// followed by ON DELETE / ON UPDATE actions and other options
+func (p *ddlParser) parseReference() (*Reference, error) {
+	_, table, err := p.qualifiedName("referenced table")
+	if err != nil {
+		return nil, err
+	}
+	ref := &Reference{Table: table}
+	if p.acceptPunct("(") {
+		if ref.Columns, err = p.nameList(); err != nil {
+			return nil, err
+		}
+	}
+	for !p.atDefinitionEnd() {
+		var action *string
+		switch {
+		case p.acceptKeywords("ON", "DELETE"):
+			action = &ref.OnDelete
+		case p.acceptKeywords("ON", "UPDATE"):
+			action = &ref.OnUpdate
+		case p.acceptKeywords("MATCH"):
+			p.skipToken()
+			continue
+		default:
+			return ref, nil
+		}
+		switch {
+		case p.acceptKeywords("SET", "NULL"):
+			*action = "SET NULL"
+		case p.acceptKeywords("SET", "DEFAULT"):
+			*action = "SET DEFAULT"
+		case p.acceptKeywords("NO", "ACTION"):
+			*action = "NO ACTION"
+		case isKeyword(p.peek(), "CASCADE", "RESTRICT"):
+			*action = strings.ToUpper(p.next().text)
+		default:
+			return nil, p.errorf(p.peek(), "expected referential action, got %s", describe(p.peek()))
+		}
+	}
+	return ref, nil
+}
+
+// nameList parses "a, b, c)" after an opening parenthesis
+func (p *ddlParser) nameList() ([]string, error) {
+	var names []string
+	for {
+		n, err := p.name("column name")
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, n)
+		// index columns may carry a length or sort order, e.g. name(10) DESC
+		for !isPunct(p.peek(), ",") && !isPunct(p.peek(), ")") && p.peek().kind != tokEOF {
+			p.skipToken()
+		}
+		if p.acceptPunct(")") {
+			return names, nil
+		}
+		if err := p.expectPunct(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// typeValues returns the members of an ENUM or SET column type
+func typeValues(toks []ddlToken) []string {
+	if len(toks) == 0 || !isKeyword(toks[0], "ENUM", "SET") {
+		return nil
+	}
+	var values []string
+	for _, t := range toks {
+		if t.kind == tokString {
+			values = append(values, t.text)
+		}
+	}
+	return values
+}
+
+// parseExpr parses a DEFAULT or ON UPDATE value: a literal, a possibly
+// signed number, a word or function call, or a parenthesized expression,
+// each optionally followed by Postgres ::type casts. value is the unquoted
+// content of a string literal, or the expression with lower-cased words;
+// literal reports which one it is.
+func (p *ddlParser) parseExpr() (value string, literal bool, err error) {
+	start := p.pos
+	t := p.peek()
+	switch {
+	case t.kind == tokString:
+		p.next()
+		value, literal = t.text, true
+	case isPunct(t, "-") || isPunct(t, "+"):
+		p.next()
+		if p.peek().kind != tokNumber {
+			return "", false, p.errorf(p.peek(), "expected number, got %s", describe(p.peek()))
+		}
+		p.next()
+	case t.kind == tokNumber:
+		p.next()
+	case isPunct(t, "("):
+		p.skipToken()
+	case t.kind == tokIdent:
+		p.next()
+		if isPunct(p.peek(), "(") {
+			p.skipToken()
+		}
+	default:
+		return "", false, p.errorf(t, "expected expression, got %s", describe(t))
+	}
+	exprEnd := p.pos
+
+	for p.acceptPunct("::") {
+		for p.peek().kind == tokIdent && !isKeyword(p.peek(), columnConstraintKeywords...) {
+			p.next()
+		}
+		if isPunct(p.peek(), "(") {
+			p.skipToken()
+		}
+		for isPunct(p.peek(), "[") {
+			p.skipToken()
+		}
+	}
+	if !literal {
+		value = renderTokens(p.toks[start:exprEnd], true)
+	}
+	return value, literal, nil
+}
+
+// atDefinitionEnd reports whether the current column definition ends here
+func (p *ddlParser) atDefinitionEnd() bool {
+	t := p.peek()
+	return t.kind == tokEOF || isPunct(t, ",") || isPunct(t, ")")
+}
+
+// skipDefinition skips the rest of a definition of the column list
+func (p *ddlParser) skipDefinition() {
+	for !p.atDefinitionEnd() {
+		p.skipToken()
+	}
+}
+
+// skipToken consumes one token, or a whole balanced (...) or [...] group
+func (p *ddlParser) skipToken() {
+	t := p.next()
+	if !isPunct(t, "(") && !isPunct(t, "[") {
+		return
+	}
+	depth := 1
+	for depth > 0 && p.peek().kind != tokEOF {
+		t = p.next()
+		switch {
+		case isPunct(t, "(") || isPunct(t, "["):
+			depth++
+		case isPunct(t, ")") || isPunct(t, "]"):
+			depth--
+		}
+	}
+}
+
+// renderTokens joins tokens back into SQL text: words are separated by a
+// space, punctuation is attached, strings are re-quoted. Bare words are
+// lower-cased when lower is set.
+func renderTokens(toks []ddlToken, lower bool) string {
+	var b strings.Builder
+	prevWord := false
+	for _, t := range toks {
+		word := t.kind != tokPunct
+		if word && prevWord {
+			b.WriteByte(' ')
+		}
+		switch t.kind {
+		case tokString:
+			b.WriteString("'" + strings.ReplaceAll(t.text, "'", "''") + "'")
+		case tokQuoted:
+			b.WriteString(`"` + t.text + `"`)
+		case tokIdent:
+			if lower {
+				b.WriteString(strings.ToLower(t.text))
+			} else {
+				b.WriteString(t.text)
+			}
+		default:
+			b.WriteString(t.text)
+		}
+		prevWord = word || isPunct(t, ")") || isPunct(t, "]")
+	}
+	return b.String()
+}
+
+// describe names a token in error messages
+func describe(t ddlToken) string {
+	switch t.kind {
+	case tokEOF:
+		return "end of statement"
+	case tokString:
+		return fmt.Sprintf("string '%s'", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
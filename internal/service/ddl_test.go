@@ -0,0 +1,252 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLexDDL(t *testing.T) {
+	tests := []struct {
+		dialect string
+		src     string
+		want    []string // kind:text of each token but EOF
+	}{
+		{DialectMySQL, "`a b` \"x\\\"y\" 'it''s'", []string{"quoted:a b", "string:x\"y", "string:it's"}},
+		{DialectPostgres, `"a""b" 'x\y' $fn$ a;b $fn$ $$ $$`, []string{`quoted:a"b`, `string:x\y`, "string: a;b ", "string: "}},
+		{DialectSQLite, "[a b] -- comment\n/* block */ x::int", []string{"quoted:a b", "ident:x", "punct:::", "ident:int"}},
+		{DialectMySQL, "# comment\n-1.5e3, 2", []string{"punct:-", "number:1.5e3", "punct:,", "number:2"}},
+	}
+	kinds := map[ddlTokenKind]string{tokIdent: "ident", tokQuoted: "quoted", tokString: "string", tokNumber: "number", tokPunct: "punct"}
+	for _, tt := range tests {
+		toks, err := lexDDL("", tt.dialect, tt.src)
+		if err != nil {
+			t.Errorf("%s %q: %v", tt.dialect, tt.src, err)
+			continue
+		}
+		var got []string
+		for _, tok := range toks[:len(toks)-1] {
+			got = append(got, kinds[tok.kind]+":"+tok.text)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %q:\n got %q\nwant %q", tt.dialect, tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestLexDDLErrors(t *testing.T) {
+	tests := []struct {
+		dialect string
+		src     string
+		want    string
+	}{
+		{DialectMySQL, "CREATE TABLE t (\n  a varchar(10) DEFAULT 'x", "2:25: unterminated '' literal"},
+		{DialectPostgres, "COMMENT ON TABLE t IS $a$ text", "1:23: unterminated"},
+		{DialectMySQL, "/* open", "1:1: unterminated"},
+	}
+	for _, tt := range tests {
+		_, err := lexDDL("", tt.dialect, tt.src)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("%q: error %v, want %s...", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestParseDDLColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		src     string
+		want    Column // of the last column of the last table
+	}{
+		{
+			name:    "mysql modifiers",
+			dialect: DialectMySQL,
+			src:     "CREATE TABLE `t` (`id` BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'key')",
+			want:    Column{Name: "id", Type: "bigint(20) unsigned", NotNull: true, AutoIncrement: true, Comment: "key"},
+		},
+		{
+			name:    "mysql enum",
+			dialect: DialectMySQL,
+			src:     "CREATE TABLE t (s enum('a','b') DEFAULT 'a')",
+			want:    Column{Name: "s", Type: "enum('a','b')", HasDefault: true, Default: "a", DefaultQuoted: true, Values: []string{"a", "b"}},
+		},
+		{
+			name:    "mysql on update",
+			dialect: DialectMySQL,
+			src:     "CREATE TABLE t (u datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP)",
+			want:    Column{Name: "u", Type: "datetime", NotNull: true, HasDefault: true, Default: "current_timestamp", OnUpdate: "current_timestamp"},
+		},
+		{
+			name:    "named inline reference",
+			dialect: DialectPostgres,
+			src:     "CREATE TABLE u (id int PRIMARY KEY); CREATE TABLE t (u_id int CONSTRAINT fk_u REFERENCES u (id) ON DELETE CASCADE)",
+			want: Column{Name: "u_id", Type: "int", ReferenceName: "fk_u",
+				References: &Reference{Table: "u", Columns: []string{"id"}, OnDelete: "CASCADE"}},
+		},
+		{
+			name:    "constraint name does not carry over",
+			dialect: DialectPostgres,
+			src:     "CREATE TABLE u (id int PRIMARY KEY); CREATE TABLE t (u_id int CONSTRAINT nn NOT NULL REFERENCES u)",
+			want:    Column{Name: "u_id", Type: "int", NotNull: true, References: &Reference{Table: "u"}},
+		},
+		{
+			name:    "postgres enum type",
+			dialect: DialectPostgres,
+			src:     "CREATE TYPE mood AS ENUM ('sad', 'ok'); CREATE TABLE t (m mood DEFAULT 'ok'::mood)",
+			want:    Column{Name: "m", Type: "mood", HasDefault: true, Default: "ok", DefaultQuoted: true, Values: []string{"sad", "ok"}},
+		},
+		{
+			name:    "mysql generated column",
+			dialect: DialectMySQL,
+			src:     "CREATE TABLE t (p int, q int, total int AS (`P` * q) STORED NOT NULL)",
+			want:    Column{Name: "total", Type: "int", NotNull: true, Generated: "(`p`*q) stored"},
+		},
+		{
+			name:    "postgres generated column",
+			dialect: DialectPostgres,
+			src:     `CREATE TABLE t (a text, "B" text GENERATED ALWAYS AS (upper("A" || 'x')) STORED)`,
+			want:    Column{Name: "B", Type: "text", Generated: `(upper("A"||'x')) stored`},
+		},
+		{
+			name:    "postgres identity is not generated",
+			dialect: DialectPostgres,
+			src:     "CREATE TABLE t (id int GENERATED ALWAYS AS IDENTITY (START WITH 10))",
+			want:    Column{Name: "id", Type: "int", AutoIncrement: true},
+		},
+		{
+			name:    "sqlite autoincrement",
+			dialect: DialectSQLite,
+			src:     `CREATE TABLE "t" ("id" INTEGER PRIMARY KEY AUTOINCREMENT)`,
+			want:    Column{Name: "id", Type: "integer", PrimaryKey: true, AutoIncrement: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := ParseDDL("schema.sql", tt.src, tt.dialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			table := schema.Tables[len(schema.Tables)-1]
+			got := *table.Columns[len(table.Columns)-1]
+			got.Line, got.Col = 0, 0
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDDLTable(t *testing.T) {
+	src := `
+CREATE TABLE IF NOT EXISTS orders (
+  id bigint NOT NULL,
+  user_id bigint NOT NULL,
+  note text,
+  PRIMARY KEY (id),
+  UNIQUE KEY uk_note (note(10)),
+  KEY idx_user USING BTREE (user_id),
+  CONSTRAINT fk_orders_owner FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  CHECK (id > 0)
+) ENGINE=InnoDB COMMENT='Orders';
+CREATE INDEX idx_note ON orders (note);
+`
+	schema, err := ParseDDL("schema.sql", src, DialectMySQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := schema.Lookup("ORDERS")
+	if table == nil {
+		t.Fatal("orders is not found by a case-insensitive lookup")
+	}
+	if table.Comment != "Orders" || !reflect.DeepEqual(table.PrimaryKey, []string{"id"}) {
+		t.Errorf("comment %q, primary key %v", table.Comment, table.PrimaryKey)
+	}
+	wantIndexes := []Index{
+		{Name: "uk_note", Columns: []string{"note"}, Unique: true},
+		{Name: "idx_user", Columns: []string{"user_id"}, Method: "btree"},
+		{Name: "idx_note", Columns: []string{"note"}},
+	}
+	if len(table.Indexes) != len(wantIndexes) {
+		t.Fatalf("got %d indexes, want %d", len(table.Indexes), len(wantIndexes))
+	}
+	for i, idx := range table.Indexes {
+		if !reflect.DeepEqual(*idx, wantIndexes[i]) {
+			t.Errorf("index %d:\n got %+v\nwant %+v", i, *idx, wantIndexes[i])
+		}
+	}
+	wantFK := ForeignKey{Name: "fk_orders_owner", Columns: []string{"user_id"},
+		Reference: Reference{Table: "users", Columns: []string{"id"}, OnDelete: "CASCADE"}}
+	if len(table.ForeignKeys) != 1 || !reflect.DeepEqual(*table.ForeignKeys[0], wantFK) {
+		t.Errorf("foreign keys %+v, want %+v", table.ForeignKeys, wantFK)
+	}
+}
+
+func TestParseDDLLike(t *testing.T) {
+	src := `
+CREATE TABLE users (
+  id int NOT NULL DEFAULT 0,
+  email varchar(64) COMMENT 'login',
+  team_id int REFERENCES teams (id),
+  PRIMARY KEY (id),
+  KEY idx_email (email)
+) COMMENT='People';
+`
+	tests := []struct {
+		dialect string
+		create  string
+		want    string
+	}{
+		{DialectMySQL, "CREATE TABLE staff LIKE users", "CREATE TABLE `staff` (\n" +
+			"  `id` int NOT NULL DEFAULT 0,\n" +
+			"  `email` varchar(64) COMMENT 'login',\n" +
+			"  `team_id` int,\n" +
+			"  PRIMARY KEY (`id`),\n" +
+			"  KEY `idx_email` (`email`)\n" +
+			") COMMENT='People';\n"},
+		{DialectPostgres, "CREATE TABLE staff (LIKE users, hired date)", `CREATE TABLE "staff" (
+  "id" int NOT NULL,
+  "email" varchar(64),
+  "team_id" int,
+  "hired" date
+);
+`},
+		{DialectPostgres, "CREATE TABLE staff (LIKE users INCLUDING ALL EXCLUDING COMMENTS)", `CREATE TABLE "staff" (
+  "id" int NOT NULL DEFAULT 0,
+  "email" varchar(64),
+  "team_id" int,
+  PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_staff_email" ON "staff" ("email");
+`},
+	}
+	for _, tt := range tests {
+		schema, err := ParseDDL("schema.sql", src+tt.create, tt.dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := WriteDDL(&Schema{Tables: []*Table{schema.Lookup("staff")}}, tt.dialect)
+		if got != tt.want {
+			t.Errorf("%s %q:\n got %s\nwant %s", tt.dialect, tt.create, got, tt.want)
+		}
+	}
+}
+
+func TestParseDDLErrors(t *testing.T) {
+	tests := []struct {
+		dialect string
+		src     string
+		want    string
+	}{
+		{DialectMySQL, "CREATE TABLE t (a int", `schema.sql:1:16: unclosed "("`},
+		{DialectPostgres, "CREATE TYPE mood AS ENUM (1)", "schema.sql:1:27: expected enum value"},
+		{DialectMySQL, "CREATE TABLE t LIKE u", "schema.sql:1:21: table u of LIKE is not defined"},
+		{DialectSQLite, "CREATE TABLE t (a int AS a)", `schema.sql:1:26: expected "(", got "a"`},
+	}
+	for _, tt := range tests {
+		_, err := ParseDDL("schema.sql", tt.src, tt.dialect)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("%q: error %v, want %s...", tt.src, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,497 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// isAlterTable reports whether the statement is ALTER [modifiers] TABLE
+func (p *ddlParser) isAlterTable() bool {
+	for i := 1; i < len(p.toks); i++ {
+		switch t := p.toks[i]; {
+		case isKeyword(t, "TABLE"):
+			return true
+		case !isKeyword(t, "ONLINE", "OFFLINE", "IGNORE"):
+			return false
+		}
+	}
+	return false
+}
+
+// parseAlterTable applies the comma-separated actions of an ALTER TABLE
+// statement. Tables not declared earlier are ignored.
+func (p *ddlParser) parseAlterTable(schema *Schema) error {
+	for !isKeyword(p.next(), "TABLE") {
+		// ALTER and modifiers such as IGNORE
+	}
+	p.acceptKeywords("IF", "EXISTS")
+	p.acceptKeywords("ONLY")
+	_, name, err := p.qualifiedName("table name")
+	if err != nil {
+		return err
+	}
+	table := schema.Lookup(name)
+	if table == nil {
+		return nil
+	}
+
+	for {
+		if err := p.parseAlterAction(schema, table); err != nil {
+			return err
+		}
+		p.skipDefinition()
+		if !p.acceptPunct(",") {
+			return nil
+		}
+	}
+}
+
+// parseAlterAction applies one action of ALTER TABLE: ADD, DROP, MODIFY,
+// CHANGE, RENAME and ALTER COLUMN, or a table COMMENT. Other actions, such
+// as ENGINE or OWNER TO, are skipped.
+func (p *ddlParser) parseAlterAction(schema *Schema, table *Table) error {
+	switch {
+	case p.acceptKeywords("ADD"):
+		return p.parseAddAction(table)
+	case p.acceptKeywords("DROP"):
+		return p.parseDropAction(schema, table)
+	case p.acceptKeywords("MODIFY"):
+		p.acceptKeywords("COLUMN")
+		at := p.peek()
+		col, err := p.parseColumn()
+		if err != nil {
+			return err
+		}
+		i := table.columnIndex(col.Name)
+		if i < 0 {
+			return p.errorf(at, "table %s has no column %s", table.Name, col.Name)
+		}
+		inheritKeys(table.Columns[i], col)
+		table.Columns[i] = col
+		return p.positionColumn(table, col)
+	case p.acceptKeywords("CHANGE"):
+		p.acceptKeywords("COLUMN")
+		at := p.peek()
+		old, err := p.name("column name")
+		if err != nil {
+			return err
+		}
+		i := table.columnIndex(old)
+		if i < 0 {
+			return p.errorf(at, "table %s has no column %s", table.Name, old)
+		}
+		col, err := p.parseColumn()
+		if err != nil {
+			return err
+		}
+		inheritKeys(table.Columns[i], col)
+		table.Columns[i] = col
+		schema.renameColumn(table, old, col.Name)
+		return p.positionColumn(table, col)
+	case p.acceptKeywords("RENAME"):
+		return p.parseRenameAction(schema, table)
+	case p.acceptKeywords("ALTER"):
+		p.acceptKeywords("COLUMN")
+		at := p.peek()
+		name, err := p.name("column name")
+		if err != nil {
+			return err
+		}
+		i := table.columnIndex(name)
+		if i < 0 {
+			return p.errorf(at, "table %s has no column %s", table.Name, name)
+		}
+		return p.parseAlterColumn(table.Columns[i])
+	case p.acceptKeywords("COMMENT"):
+		p.acceptPunct("=")
+		if p.peek().kind != tokString {
+			return p.errorf(p.peek(), "expected comment string, got %s", describe(p.peek()))
+		}
+		table.Comment = p.next().text
+	}
+	return nil
+}
+
+// parseAddAction parses ADD [COLUMN] column [FIRST | AFTER column], the
+// MySQL ADD (column, ...) list, or ADD of a key, index or constraint
+func (p *ddlParser) parseAddAction(table *Table) error {
+	column := p.acceptKeywords("COLUMN")
+	if !column && isKeyword(p.peek(), tableConstraintKeywords...) {
+		if isKeyword(p.peek(), "PRIMARY") || isKeyword(p.peekAt(2), "PRIMARY") {
+			// the new primary key replaces the one declared on columns
+			for _, c := range table.Columns {
+				c.PrimaryKey = false
+			}
+		}
+		return p.parseTableConstraint(table)
+	}
+	ifNotExists := p.acceptKeywords("IF", "NOT", "EXISTS")
+
+	add := func() error {
+		at := p.peek()
+		col, err := p.parseColumn()
+		if err != nil {
+			return err
+		}
+		if table.columnIndex(col.Name) >= 0 {
+			if ifNotExists {
+				return nil
+			}
+			return p.errorf(at, "table %s already has a column %s", table.Name, col.Name)
+		}
+		table.Columns = append(table.Columns, col)
+		return p.positionColumn(table, col)
+	}
+	if !p.acceptPunct("(") {
+		return add()
+	}
+	for {
+		if err := add(); err != nil {
+			return err
+		}
+		if p.acceptPunct(")") {
+			return nil
+		}
+		if err := p.expectPunct(","); err != nil {
+			return err
+		}
+	}
+}
+
+// parseDropAction parses DROP of a column, the primary key, an index or a
+// constraint
+func (p *ddlParser) parseDropAction(schema *Schema, table *Table) error {
+	switch {
+	case p.acceptKeywords("PRIMARY", "KEY"):
+		table.dropPrimaryKey()
+	case p.acceptKeywords("INDEX"), p.acceptKeywords("KEY"):
+		name, err := p.name("index name")
+		if err != nil {
+			return err
+		}
+		table.dropIndex(name)
+	case p.acceptKeywords("FOREIGN", "KEY"), p.acceptKeywords("CONSTRAINT"), p.acceptKeywords("CHECK"):
+		p.acceptKeywords("IF", "EXISTS")
+		name, err := p.name("constraint name")
+		if err != nil {
+			return err
+		}
+		table.dropConstraint(name)
+	default:
+		p.acceptKeywords("COLUMN")
+		ifExists := p.acceptKeywords("IF", "EXISTS")
+		at := p.peek()
+		name, err := p.name("column name")
+		if err != nil {
+			return err
+		}
+		if table.columnIndex(name) < 0 {
+			if ifExists {
+				return nil
+			}
+			return p.errorf(at, "table %s has no column %s", table.Name, name)
+		}
+		schema.dropColumn(table, name)
+	}
+	return nil
+}
+
+// parseRenameAction parses RENAME [TO|AS] table, RENAME [COLUMN] a TO b
+// and RENAME INDEX|KEY|CONSTRAINT a TO b
+func (p *ddlParser) parseRenameAction(schema *Schema, table *Table) error {
+	renamePair := func(what string) (string, string, error) {
+		old, err := p.name(what)
+		if err != nil {
+			return "", "", err
+		}
+		if !p.acceptKeywords("TO") {
+			return "", "", p.errorf(p.peek(), "expected TO, got %s", describe(p.peek()))
+		}
+		name, err := p.name(what)
+		return old, name, err
+	}
+
+	switch {
+	case p.acceptKeywords("INDEX"), p.acceptKeywords("KEY"), p.acceptKeywords("CONSTRAINT"):
+		old, name, err := renamePair("index name")
+		if err != nil {
+			return err
+		}
+		table.renameIndex(old, name)
+	case p.acceptKeywords("TO"), p.acceptKeywords("AS"):
+		_, name, err := p.qualifiedName("table name")
+		if err != nil {
+			return err
+		}
+		schema.renameTable(table, name)
+	case p.acceptKeywords("COLUMN"), isKeyword(p.peekAt(1), "TO"):
+		at := p.peek()
+		old, name, err := renamePair("column name")
+		if err != nil {
+			return err
+		}
+		i := table.columnIndex(old)
+		if i < 0 {
+			return p.errorf(at, "table %s has no column %s", table.Name, old)
+		}
+		table.Columns[i].Name = name
+		schema.renameColumn(table, old, name)
+	default:
+		// MySQL RENAME new_name
+		_, name, err := p.qualifiedName("table name")
+		if err != nil {
+			return err
+		}
+		schema.renameTable(table, name)
+	}
+	return nil
+}
+
+// parseAlterColumn applies ALTER COLUMN actions: SET/DROP DEFAULT,
+// SET/DROP NOT NULL and [SET DATA] TYPE
+func (p *ddlParser) parseAlterColumn(col *Column) error {
+	switch {
+	case p.acceptKeywords("SET", "DEFAULT"):
+		value, literal, err := p.parseExpr()
+		if err != nil {
+			return err
+		}
+		col.HasDefault, col.Default, col.DefaultQuoted = true, value, literal
+	case p.acceptKeywords("DROP", "DEFAULT"):
+		col.HasDefault, col.Default, col.DefaultQuoted = false, "", false
+	case p.acceptKeywords("SET", "NOT", "NULL"):
+		col.NotNull = true
+	case p.acceptKeywords("DROP", "NOT", "NULL"):
+		col.NotNull = false
+	case p.acceptKeywords("SET", "DATA", "TYPE"), p.acceptKeywords("TYPE"):
+		start := p.pos
+		for !p.atDefinitionEnd() && !isKeyword(p.peek(), "USING", "COLLATE") {
+			p.skipToken()
+		}
+		col.Type = renderTokens(p.toks[start:p.pos], true)
+		col.Values = nil
+	}
+	return nil
+}
+
+// positionColumn moves col as requested by an optional FIRST or AFTER
+// column clause
+func (p *ddlParser) positionColumn(table *Table, col *Column) error {
+	pos := 0
+	switch {
+	case p.acceptKeywords("FIRST"):
+	case p.acceptKeywords("AFTER"):
+		at := p.peek()
+		name, err := p.name("column name")
+		if err != nil {
+			return err
+		}
+		if table.columnIndex(name) < 0 {
+			return p.errorf(at, "table %s has no column %s", table.Name, name)
+		}
+		table.Columns = slices.DeleteFunc(table.Columns, func(c *Column) bool { return c == col })
+		pos = table.columnIndex(name) + 1
+		table.Columns = slices.Insert(table.Columns, pos, col)
+		return nil
+	default:
+		return nil
+	}
+	table.Columns = slices.DeleteFunc(table.Columns, func(c *Column) bool { return c == col })
+	table.Columns = slices.Insert(table.Columns, pos, col)
+	return nil
+}
+
+// parseRenameTable applies RENAME TABLE a TO b [, c TO d ...]
+func (p *ddlParser) parseRenameTable(schema *Schema) error {
+	for {
+		_, old, err := p.qualifiedName("table name")
+		if err != nil {
+			return err
+		}
+		if !p.acceptKeywords("TO") {
+			return p.errorf(p.peek(), "expected TO, got %s", describe(p.peek()))
+		}
+		_, name, err := p.qualifiedName("table name")
+		if err != nil {
+			return err
+		}
+		if table := schema.Lookup(old); table != nil {
+			schema.renameTable(table, name)
+		}
+		if !p.acceptPunct(",") {
+			return nil
+		}
+	}
+}
+
+// parseDrop applies DROP TABLE and DROP INDEX statements; other DROP
+// statements are ignored
+func (p *ddlParser) parseDrop(schema *Schema) error {
+	p.next()
+	p.acceptKeywords("TEMPORARY")
+	switch {
+	case p.acceptKeywords("TABLE"):
+		p.acceptKeywords("IF", "EXISTS")
+		for {
+			_, name, err := p.qualifiedName("table name")
+			if err != nil {
+				return err
+			}
+			schema.Tables = slices.DeleteFunc(schema.Tables, func(t *Table) bool { return strings.EqualFold(t.Name, name) })
+			if !p.acceptPunct(",") {
+				return nil
+			}
+		}
+	case p.acceptKeywords("INDEX"):
+		p.acceptKeywords("CONCURRENTLY")
+		p.acceptKeywords("IF", "EXISTS")
+		_, name, err := p.qualifiedName("index name")
+		if err != nil {
+			return err
+		}
+		// MySQL names the table, Postgres index names are unique per schema
+		tableName := ""
+		if p.acceptKeywords("ON") {
+			if _, tableName, err = p.qualifiedName("table name"); err != nil {
+				return err
+			}
+		}
+		for _, t := range schema.Tables {
+			if tableName == "" || strings.EqualFold(t.Name, tableName) {
+				t.dropIndex(name)
+			}
+		}
+	}
+	return nil
+}
+
+// inheritKeys carries the keys of a column redefined by MODIFY or CHANGE
+// over to its new definition: like MySQL, only the column itself changes
+func inheritKeys(old, col *Column) {
+	col.PrimaryKey = col.PrimaryKey || old.PrimaryKey
+	col.Unique = col.Unique || old.Unique
+	if col.References == nil {
+		col.References, col.ReferenceName = old.References, old.ReferenceName
+	}
+}
+
+func (t *Table) columnIndex(name string) int {
+	return slices.IndexFunc(t.Columns, func(c *Column) bool { return strings.EqualFold(c.Name, name) })
+}
+
+func (t *Table) dropPrimaryKey() {
+	t.PrimaryKey = nil
+	for _, c := range t.Columns {
+		c.PrimaryKey = false
+	}
+}
+
+func (t *Table) dropIndex(name string) {
+	t.Indexes = slices.DeleteFunc(t.Indexes, func(idx *Index) bool { return strings.EqualFold(idx.Name, name) })
+}
+
+// dropConstraint drops the index or foreign key named name. Postgres names
+// the primary key constraint <table>_pkey by default.
+func (t *Table) dropConstraint(name string) {
+	t.dropIndex(name)
+	// Unnamed foreign keys go by the names MySQL (<table>_ibfk_<n>) and
+	// Postgres (<table>_<columns>_fkey) give them
+	n := 0
+	t.ForeignKeys = slices.DeleteFunc(t.ForeignKeys, func(fk *ForeignKey) bool {
+		if fk.Name != "" {
+			return strings.EqualFold(fk.Name, name)
+		}
+		n++
+		return strings.EqualFold(name, fmt.Sprintf("%s_ibfk_%d", t.Name, n)) ||
+			strings.EqualFold(name, t.Name+"_"+strings.Join(fk.Columns, "_")+"_fkey")
+	})
+	for _, col := range t.Columns {
+		if col.References == nil {
+			continue
+		}
+		if strings.EqualFold(name, col.ReferenceName) ||
+			col.ReferenceName == "" && strings.EqualFold(name, t.Name+"_"+col.Name+"_fkey") {
+			col.References, col.ReferenceName = nil, ""
+		}
+	}
+	if strings.EqualFold(name, t.Name+"_pkey") {
+		t.dropPrimaryKey()
+	}
+}
+
+func (t *Table) renameIndex(old, name string) {
+	for _, idx := range t.Indexes {
+		if strings.EqualFold(idx.Name, old) {
+			idx.Name = name
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		if strings.EqualFold(fk.Name, old) {
+			fk.Name = name
+		}
+	}
+}
+
+// renameTable renames table and the references of foreign keys to it
+func (s *Schema) renameTable(table *Table, name string) {
+	s.eachReference(func(ref *Reference) {
+		if strings.EqualFold(ref.Table, table.Name) {
+			ref.Table = name
+		}
+	})
+	table.Name = name
+}
+
+// renameColumn renames the column old of table in its keys and indexes and
+// in foreign keys referencing it
+func (s *Schema) renameColumn(table *Table, old, name string) {
+	rename := func(columns []string) {
+		for i, c := range columns {
+			if strings.EqualFold(c, old) {
+				columns[i] = name
+			}
+		}
+	}
+	rename(table.PrimaryKey)
+	for _, idx := range table.Indexes {
+		rename(idx.Columns)
+	}
+	for _, fk := range table.ForeignKeys {
+		rename(fk.Columns)
+	}
+	s.eachReference(func(ref *Reference) {
+		if strings.EqualFold(ref.Table, table.Name) {
+			rename(ref.Columns)
+		}
+	})
+}
+
+// dropColumn drops the column name of table, removing it from indexes and
+// dropping the indexes and foreign keys left without columns
+func (s *Schema) dropColumn(table *Table, name string) {
+	matches := func(c string) bool { return strings.EqualFold(c, name) }
+	table.Columns = slices.DeleteFunc(table.Columns, func(c *Column) bool { return matches(c.Name) })
+	table.PrimaryKey = slices.DeleteFunc(table.PrimaryKey, matches)
+	for _, idx := range table.Indexes {
+		idx.Columns = slices.DeleteFunc(idx.Columns, matches)
+	}
+	table.Indexes = slices.DeleteFunc(table.Indexes, func(idx *Index) bool { return len(idx.Columns) == 0 })
+	table.ForeignKeys = slices.DeleteFunc(table.ForeignKeys, func(fk *ForeignKey) bool {
+		return slices.ContainsFunc(fk.Columns, matches)
+	})
+}
+
+// eachReference calls fn with every foreign key target of the schema
+func (s *Schema) eachReference(fn func(ref *Reference)) {
+	for _, t := range s.Tables {
+		for _, fk := range t.ForeignKeys {
+			fn(&fk.Reference)
+		}
+		for _, c := range t.Columns {
+			if c.References != nil {
+				fn(c.References)
+			}
+		}
+	}
+}
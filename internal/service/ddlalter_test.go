@@ -0,0 +1,103 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDDLAlterTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		src     string
+		want    string // CREATE TABLE statement of the last table
+	}{
+		{
+			name:    "add, modify and drop columns",
+			dialect: DialectMySQL,
+			src: `CREATE TABLE t (id int NOT NULL, a int, b int, PRIMARY KEY (id));
+ALTER TABLE t ADD COLUMN c varchar(10) NOT NULL AFTER id, MODIFY a bigint DEFAULT 1, DROP COLUMN b;`,
+			want: "CREATE TABLE `t` (\n  `id` int NOT NULL,\n  `c` varchar(10) NOT NULL,\n  `a` bigint DEFAULT 1,\n  PRIMARY KEY (`id`)\n);\n",
+		},
+		{
+			name:    "rename column and table",
+			dialect: DialectPostgres,
+			src: `CREATE TABLE t (id int PRIMARY KEY, a int);
+CREATE INDEX idx_a ON t (a);
+ALTER TABLE t RENAME COLUMN a TO b;
+ALTER TABLE t RENAME TO u;`,
+			want: "CREATE TABLE \"u\" (\n  \"id\" int NOT NULL,\n  \"b\" int,\n  PRIMARY KEY (\"id\")\n);\nCREATE INDEX \"idx_a\" ON \"u\" (\"b\");\n",
+		},
+		{
+			name:    "add and drop keys",
+			dialect: DialectMySQL,
+			src: `CREATE TABLE p (id int NOT NULL, PRIMARY KEY (id));
+CREATE TABLE t (id int NOT NULL, p_id int, name varchar(10), KEY idx_name (name));
+ALTER TABLE t ADD PRIMARY KEY (id), ADD UNIQUE KEY uk_name (name), DROP INDEX idx_name,
+  ADD CONSTRAINT fk_p FOREIGN KEY (p_id) REFERENCES p (id) ON DELETE SET NULL;`,
+			want: "CREATE TABLE `t` (\n  `id` int NOT NULL,\n  `p_id` int,\n  `name` varchar(10),\n  PRIMARY KEY (`id`),\n  UNIQUE KEY `uk_name` (`name`),\n  CONSTRAINT `fk_p` FOREIGN KEY (`p_id`) REFERENCES `p` (`id`) ON DELETE SET NULL\n);\n",
+		},
+		{
+			name:    "drop a named inline reference",
+			dialect: DialectPostgres,
+			src: `CREATE TABLE p (id int PRIMARY KEY);
+CREATE TABLE t (id int PRIMARY KEY, p_id int CONSTRAINT fk_p REFERENCES p (id));
+ALTER TABLE t DROP CONSTRAINT fk_p;`,
+			want: "CREATE TABLE \"t\" (\n  \"id\" int NOT NULL,\n  \"p_id\" int,\n  PRIMARY KEY (\"id\")\n);\n",
+		},
+		{
+			name:    "postgres alter column",
+			dialect: DialectPostgres,
+			src: `CREATE TABLE t (id int PRIMARY KEY, a int);
+ALTER TABLE t ALTER COLUMN a TYPE bigint, ALTER COLUMN a SET NOT NULL, ALTER COLUMN a SET DEFAULT 0;`,
+			want: "CREATE TABLE \"t\" (\n  \"id\" int NOT NULL,\n  \"a\" bigint NOT NULL DEFAULT 0,\n  PRIMARY KEY (\"id\")\n);\n",
+		},
+		{
+			name:    "dropped table",
+			dialect: DialectSQLite,
+			src: `CREATE TABLE a (id integer PRIMARY KEY);
+CREATE TABLE b (id integer PRIMARY KEY);
+DROP TABLE IF EXISTS b;`,
+			want: "CREATE TABLE \"a\" (\n  \"id\" integer NOT NULL,\n  PRIMARY KEY (\"id\")\n);\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := ParseDDL("schema.sql", tt.src, tt.dialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := CreateTableSQL(schema.Tables[len(schema.Tables)-1], tt.dialect, nil)
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDDLDirReplaysMigrations(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"20240101000000_users.up.sql":   "CREATE TABLE users (id int PRIMARY KEY, name text);",
+		"20240101000000_users.down.sql": "DROP TABLE users;",
+		"20240102000000_email.up.sql":   "ALTER TABLE users ADD COLUMN email text NOT NULL;",
+		"20240103000000_name.sql":       "ALTER TABLE users DROP COLUMN name;",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	schema, err := ParseDDLDir(dir, DialectPostgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "CREATE TABLE \"users\" (\n  \"id\" int NOT NULL,\n  \"email\" text NOT NULL,\n  PRIMARY KEY (\"id\")\n);\n"
+	if len(schema.Tables) != 1 {
+		t.Fatalf("got %d tables, want 1", len(schema.Tables))
+	}
+	if got := CreateTableSQL(schema.Tables[0], DialectPostgres, nil); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
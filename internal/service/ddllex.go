@@ -0,0 +1,270 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ddlTokenKind classifies a DDL token
+type ddlTokenKind int
+
+const (
+	tokEOF    ddlTokenKind = iota
+	tokIdent               // Bare word: keyword or identifier
+	tokQuoted              // Quoted identifier: `a`, "a" or [a]
+	tokString              // String literal, text holds the unescaped value
+	tokNumber              // Numeric literal
+	tokPunct               // Operator or punctuation, "::" is a single token
+)
+
+// ddlToken is a lexical token with the position of its first character
+type ddlToken struct {
+	kind ddlTokenKind
+	text string
+	line int
+	col  int
+}
+
+// DDLError is a syntax error in a DDL file
+type DDLError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error formats the error as file:line:column: message
+func (e *DDLError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// ddlLexer splits DDL source into tokens, dropping whitespace and comments.
+// Quoting rules follow the dialect: MySQL treats "..." as a string and
+// honors backslash escapes, Postgres and SQLite treat "..." as an
+// identifier, SQLite also accepts [name], Postgres $tag$ strings.
+type ddlLexer struct {
+	file    string
+	dialect string
+	src     string
+	off     int
+	line    int
+	col     int
+}
+
+// lexDDL tokenizes src; the last token is always tokEOF
+func lexDDL(file, dialect, src string) ([]ddlToken, error) {
+	lx := &ddlLexer{file: file, dialect: dialect, src: src, line: 1, col: 1}
+	var toks []ddlToken
+	for {
+		tok, err := lx.next()
+		if err != nil {
+			return nil, err
+		}
+		toks = append(toks, tok)
+		if tok.kind == tokEOF {
+			return toks, nil
+		}
+	}
+}
+
+func (lx *ddlLexer) errorf(line, col int, format string, args ...any) error {
+	return &DDLError{File: lx.file, Line: line, Column: col, Message: fmt.Sprintf(format, args...)}
+}
+
+// peekByte returns the byte i positions ahead, or 0 past the end
+func (lx *ddlLexer) peekByte(i int) byte {
+	if lx.off+i < len(lx.src) {
+		return lx.src[lx.off+i]
+	}
+	return 0
+}
+
+// advance consumes n bytes, tracking line and column
+func (lx *ddlLexer) advance(n int) {
+	for ; n > 0 && lx.off < len(lx.src); n-- {
+		if lx.src[lx.off] == '\n' {
+			lx.line++
+			lx.col = 1
+		} else {
+			lx.col++
+		}
+		lx.off++
+	}
+}
+
+func (lx *ddlLexer) next() (ddlToken, error) {
+	if err := lx.skipSpaceAndComments(); err != nil {
+		return ddlToken{}, err
+	}
+	tok := ddlToken{line: lx.line, col: lx.col}
+	if lx.off >= len(lx.src) {
+		tok.kind = tokEOF
+		return tok, nil
+	}
+
+	c := lx.src[lx.off]
+	switch {
+	case c == '\'':
+		tok.kind = tokString
+		text, err := lx.quoted('\'', lx.dialect == DialectMySQL)
+		tok.text = text
+		return tok, err
+	case (c == 'e' || c == 'E') && lx.peekByte(1) == '\'' && lx.dialect == DialectPostgres:
+		// E'...' escape string
+		lx.advance(1)
+		tok.kind = tokString
+		text, err := lx.quoted('\'', true)
+		tok.text = text
+		return tok, err
+	case c == '"':
+		tok.kind = tokQuoted
+		if lx.dialect == DialectMySQL {
+			tok.kind = tokString
+		}
+		text, err := lx.quoted('"', lx.dialect == DialectMySQL)
+		tok.text = text
+		return tok, err
+	case c == '`':
+		tok.kind = tokQuoted
+		text, err := lx.quoted('`', false)
+		tok.text = text
+		return tok, err
+	case c == '[' && lx.dialect == DialectSQLite:
+		end := strings.IndexByte(lx.src[lx.off+1:], ']')
+		if end < 0 {
+			return tok, lx.errorf(tok.line, tok.col, "unterminated [identifier]")
+		}
+		tok.kind = tokQuoted
+		tok.text = lx.src[lx.off+1 : lx.off+1+end]
+		lx.advance(end + 2)
+		return tok, nil
+	case c == '$' && lx.dialect == DialectPostgres && lx.dollarTag() != "":
+		tag := lx.dollarTag()
+		end := strings.Index(lx.src[lx.off+len(tag):], tag)
+		if end < 0 {
+			return tok, lx.errorf(tok.line, tok.col, "unterminated %s string", tag)
+		}
+		tok.kind = tokString
+		tok.text = lx.src[lx.off+len(tag) : lx.off+len(tag)+end]
+		lx.advance(len(tag)*2 + end)
+		return tok, nil
+	case isDigit(c) || (c == '.' && isDigit(lx.peekByte(1))):
+		tok.kind = tokNumber
+		start := lx.off
+		for lx.off < len(lx.src) && (isWordByte(lx.src[lx.off]) || lx.src[lx.off] == '.' ||
+			((lx.src[lx.off] == '+' || lx.src[lx.off] == '-') && (lx.src[lx.off-1] == 'e' || lx.src[lx.off-1] == 'E'))) {
+			lx.advance(1)
+		}
+		tok.text = lx.src[start:lx.off]
+		return tok, nil
+	case isWordByte(c) || c >= 0x80:
+		tok.kind = tokIdent
+		start := lx.off
+		for lx.off < len(lx.src) && (isWordByte(lx.src[lx.off]) || lx.src[lx.off] == '$' || lx.src[lx.off] >= 0x80) {
+			lx.advance(1)
+		}
+		tok.text = lx.src[start:lx.off]
+		return tok, nil
+	case c == ':' && lx.peekByte(1) == ':':
+		tok.kind = tokPunct
+		tok.text = "::"
+		lx.advance(2)
+		return tok, nil
+	default:
+		tok.kind = tokPunct
+		tok.text = string(c)
+		lx.advance(1)
+		return tok, nil
+	}
+}
+
+// skipSpaceAndComments skips whitespace, -- and # line comments and
+// /* block */ comments, which may span lines
+func (lx *ddlLexer) skipSpaceAndComments() error {
+	for lx.off < len(lx.src) {
+		c := lx.src[lx.off]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			lx.advance(1)
+		case c == '-' && lx.peekByte(1) == '-', c == '#' && lx.dialect == DialectMySQL:
+			for lx.off < len(lx.src) && lx.src[lx.off] != '\n' {
+				lx.advance(1)
+			}
+		case c == '/' && lx.peekByte(1) == '*':
+			line, col := lx.line, lx.col
+			end := strings.Index(lx.src[lx.off+2:], "*/")
+			if end < 0 {
+				return lx.errorf(line, col, "unterminated /* comment")
+			}
+			lx.advance(end + 4)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// quoted consumes a literal delimited by q, where a doubled q stands for
+// itself and, when backslash is set, \ escapes the next character
+func (lx *ddlLexer) quoted(q byte, backslash bool) (string, error) {
+	line, col := lx.line, lx.col
+	lx.advance(1)
+	var b strings.Builder
+	for lx.off < len(lx.src) {
+		c := lx.src[lx.off]
+		switch {
+		case c == q && lx.peekByte(1) == q:
+			b.WriteByte(q)
+			lx.advance(2)
+		case c == q:
+			lx.advance(1)
+			return b.String(), nil
+		case c == '\\' && backslash && lx.off+1 < len(lx.src):
+			b.WriteByte(unescapeByte(lx.src[lx.off+1]))
+			lx.advance(2)
+		default:
+			b.WriteByte(c)
+			lx.advance(1)
+		}
+	}
+	return "", lx.errorf(line, col, "unterminated %c%c literal", q, q)
+}
+
+// dollarTag returns the $tag$ opening a Postgres dollar-quoted string at
+// the current offset, or "" when there is none
+func (lx *ddlLexer) dollarTag() string {
+	i := lx.off + 1
+	for i < len(lx.src) && (isWordByte(lx.src[i]) && !isDigit(lx.src[i]) || i > lx.off+1 && isDigit(lx.src[i])) {
+		i++
+	}
+	if i < len(lx.src) && lx.src[i] == '$' {
+		return lx.src[lx.off : i+1]
+	}
+	return ""
+}
+
+func unescapeByte(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	default:
+		return c
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
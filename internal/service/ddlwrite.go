@@ -0,0 +1,288 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// WriteDDL renders the schema as CREATE TABLE statements of the dialect,
+// followed by the CREATE INDEX and COMMENT ON statements Postgres and SQLite
+// need. Referenced tables come first; foreign keys of tables referencing
+// each other are added by ALTER TABLE once both exist.
+func WriteDDL(schema *Schema, dialect string) string {
+	var sb strings.Builder
+	created := make(map[string]bool)
+	var deferred []string
+	for _, table := range creationOrder(schema, dialect) {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		var later []*ForeignKey
+		for _, fk := range tableForeignKeys(table) {
+			ref := schema.Lookup(fk.Table)
+			if dialect != DialectSQLite && ref != nil && ref != table && !created[strings.ToLower(ref.Name)] {
+				later = append(later, fk)
+			}
+		}
+		sb.WriteString(CreateTableSQL(table, dialect, later))
+		for _, fk := range later {
+			deferred = append(deferred, fmt.Sprintf("ALTER TABLE %s ADD %s;\n",
+				QuoteIdent(table.Name, dialect), ForeignKeySQL(table, fk, dialect)))
+		}
+		created[strings.ToLower(table.Name)] = true
+	}
+	if len(deferred) > 0 {
+		sb.WriteString("\n" + strings.Join(deferred, ""))
+	}
+	return sb.String()
+}
+
+// creationOrder sorts the tables so that each comes after the tables its
+// foreign keys reference, keeping the schema order otherwise. Tables in a
+// reference cycle keep their order.
+func creationOrder(schema *Schema, dialect string) []*Table {
+	if dialect == DialectSQLite {
+		// SQLite resolves foreign keys when rows are written
+		return schema.Tables
+	}
+	var order []*Table
+	done := make(map[*Table]bool)
+	for len(order) < len(schema.Tables) {
+		progress := false
+		for _, t := range schema.Tables {
+			if done[t] {
+				continue
+			}
+			ready := true
+			for _, fk := range tableForeignKeys(t) {
+				if ref := schema.Lookup(fk.Table); ref != nil && ref != t && !done[ref] {
+					ready = false
+				}
+			}
+			if ready {
+				order, done[t], progress = append(order, t), true, true
+			}
+		}
+		if !progress {
+			for _, t := range schema.Tables {
+				if !done[t] {
+					order, done[t] = append(order, t), true
+					break
+				}
+			}
+		}
+	}
+	return order
+}
+
+// CreateTableSQL renders the CREATE TABLE statement of table, with its
+// indexes and comments, leaving out the foreign keys in skip
+func CreateTableSQL(table *Table, dialect string, skip []*ForeignKey) string {
+	pk := primaryKeyColumns(table)
+	// SQLite only auto-increments an INTEGER PRIMARY KEY declared on the column
+	inlinePK := ""
+	if dialect == DialectSQLite && len(pk) == 1 {
+		if i := table.columnIndex(pk[0]); i >= 0 && table.Columns[i].AutoIncrement {
+			inlinePK = strings.ToLower(table.Columns[i].Name)
+		}
+	}
+
+	var defs []string
+	for _, col := range table.Columns {
+		def := ColumnSQL(col, dialect)
+		if strings.ToLower(col.Name) == inlinePK {
+			def = QuoteIdent(col.Name, dialect) + " integer PRIMARY KEY AUTOINCREMENT"
+		}
+		defs = append(defs, def)
+	}
+	if len(pk) > 0 && inlinePK == "" {
+		defs = append(defs, "PRIMARY KEY ("+quoteColumns(table, pk, dialect)+")")
+	}
+	var indexes []string
+	for _, idx := range table.Indexes {
+		if dialect == DialectMySQL {
+			defs = append(defs, mysqlIndexSQL(table, idx))
+		} else {
+			indexes = append(indexes, CreateIndexSQL(table, idx, dialect))
+		}
+	}
+	for _, fk := range tableForeignKeys(table) {
+		deferred := slices.ContainsFunc(skip, func(other *ForeignKey) bool {
+			return strings.EqualFold(other.Table, fk.Table) && slices.Equal(other.Columns, fk.Columns)
+		})
+		if !deferred {
+			defs = append(defs, ForeignKeySQL(table, fk, dialect))
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CREATE TABLE %s (\n  %s\n)", QuoteIdent(table.Name, dialect), strings.Join(defs, ",\n  "))
+	if dialect == DialectMySQL && table.Comment != "" {
+		sb.WriteString(" COMMENT=" + quoteString(table.Comment))
+	}
+	sb.WriteString(";\n")
+	for _, stmt := range indexes {
+		sb.WriteString(stmt + ";\n")
+	}
+	if dialect == DialectPostgres {
+		if table.Comment != "" {
+			fmt.Fprintf(&sb, "COMMENT ON TABLE %s IS %s;\n", QuoteIdent(table.Name, dialect), quoteString(table.Comment))
+		}
+		for _, col := range table.Columns {
+			if col.Comment != "" {
+				fmt.Fprintf(&sb, "COMMENT ON COLUMN %s.%s IS %s;\n", QuoteIdent(table.Name, dialect),
+					QuoteIdent(col.Name, dialect), quoteString(col.Comment))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// ColumnSQL renders a column definition. Primary keys are left to the
+// table-level PRIMARY KEY, and Postgres comments to COMMENT ON.
+func ColumnSQL(col *Column, dialect string) string {
+	parts := []string{QuoteIdent(col.Name, dialect), col.Type}
+	if dialect == DialectMySQL && len(col.Values) > 0 && !strings.Contains(col.Type, "(") {
+		quoted := make([]string, len(col.Values))
+		for i, v := range col.Values {
+			quoted[i] = quoteString(v)
+		}
+		parts[1] += "(" + strings.Join(quoted, ",") + ")"
+	}
+	if col.Generated != "" {
+		parts = append(parts, "GENERATED ALWAYS AS "+col.Generated)
+	}
+	if col.NotNull || col.PrimaryKey {
+		parts = append(parts, "NOT NULL")
+	}
+	if col.HasDefault {
+		value := col.Default
+		if col.DefaultQuoted {
+			value = quoteString(value)
+		}
+		parts = append(parts, "DEFAULT "+value)
+	}
+	if dialect == DialectMySQL && col.OnUpdate != "" {
+		parts = append(parts, "ON UPDATE "+col.OnUpdate)
+	}
+	if col.AutoIncrement {
+		switch {
+		case dialect == DialectMySQL:
+			parts = append(parts, "AUTO_INCREMENT")
+		case dialect == DialectPostgres && !strings.Contains(col.Type, "serial"):
+			parts = append(parts, "GENERATED BY DEFAULT AS IDENTITY")
+		}
+	}
+	if col.Unique {
+		parts = append(parts, "UNIQUE")
+	}
+	if col.CheckValues {
+		quoted := make([]string, len(col.Values))
+		for i, v := range col.Values {
+			quoted[i] = quoteString(v)
+		}
+		parts = append(parts, "CHECK ("+QuoteIdent(col.Name, dialect)+" IN ("+strings.Join(quoted, ",")+"))")
+	}
+	if dialect == DialectMySQL && col.Comment != "" {
+		parts = append(parts, "COMMENT "+quoteString(col.Comment))
+	}
+	return strings.Join(parts, " ")
+}
+
+// mysqlIndexSQL renders an index inside a MySQL CREATE TABLE statement
+func mysqlIndexSQL(table *Table, idx *Index) string {
+	kind := "KEY"
+	switch {
+	case idx.Class != "":
+		kind = idx.Class + " KEY"
+	case idx.Unique:
+		kind = "UNIQUE KEY"
+	}
+	s := kind + " " + QuoteIdent(indexName(table, idx), DialectMySQL) + " (" + quoteColumns(table, idx.Columns, DialectMySQL) + ")"
+	if idx.Method != "" {
+		s += " USING " + strings.ToUpper(idx.Method)
+	}
+	return s
+}
+
+// CreateIndexSQL renders a CREATE INDEX statement, without semicolon
+func CreateIndexSQL(table *Table, idx *Index, dialect string) string {
+	kind := "INDEX"
+	if idx.Unique {
+		kind = "UNIQUE INDEX"
+	}
+	s := fmt.Sprintf("CREATE %s %s ON %s", kind, QuoteIdent(indexName(table, idx), dialect), QuoteIdent(table.Name, dialect))
+	if idx.Method != "" && dialect == DialectPostgres {
+		s += " USING " + idx.Method
+	}
+	return s + " (" + quoteColumns(table, idx.Columns, dialect) + ")"
+}
+
+// ForeignKeySQL renders a FOREIGN KEY table constraint
+func ForeignKeySQL(table *Table, fk *ForeignKey, dialect string) string {
+	s := ""
+	if fk.Name != "" {
+		s = "CONSTRAINT " + QuoteIdent(fk.Name, dialect) + " "
+	}
+	s += fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s", quoteColumns(table, fk.Columns, dialect), QuoteIdent(fk.Table, dialect))
+	if len(fk.Reference.Columns) > 0 {
+		s += " (" + quoteColumns(nil, fk.Reference.Columns, dialect) + ")"
+	}
+	if fk.OnDelete != "" {
+		s += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" {
+		s += " ON UPDATE " + fk.OnUpdate
+	}
+	return s
+}
+
+// tableForeignKeys returns the table-level foreign keys followed by the
+// inline REFERENCES clauses of columns
+func tableForeignKeys(table *Table) []*ForeignKey {
+	fks := append([]*ForeignKey{}, table.ForeignKeys...)
+	for _, col := range table.Columns {
+		if col.References != nil {
+			fks = append(fks, &ForeignKey{Name: col.ReferenceName, Columns: []string{col.Name}, Reference: *col.References})
+		}
+	}
+	return fks
+}
+
+// indexName returns the name of idx, or gorm's default idx_<table>_<columns>
+// for an unnamed index
+func indexName(table *Table, idx *Index) string {
+	if idx.Name != "" {
+		return idx.Name
+	}
+	return "idx_" + table.Name + "_" + strings.Join(idx.Columns, "_")
+}
+
+// quoteColumns quotes a list of column names, spelled as declared in table
+// when it is given
+func quoteColumns(table *Table, columns []string, dialect string) string {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		if table != nil {
+			if j := table.columnIndex(c); j >= 0 {
+				c = table.Columns[j].Name
+			}
+		}
+		quoted[i] = QuoteIdent(c, dialect)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// QuoteIdent quotes an identifier: `name` for MySQL, "name" otherwise
+func QuoteIdent(name, dialect string) string {
+	if dialect == DialectMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteString quotes a SQL string literal
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQL dialects understood by model generation, each backed by a
+// lib/db/<dialect> connector package in generated projects
+const (
+	DialectMySQL    = "mysql"
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// Dialects lists the supported SQL dialects
+var Dialects = []string{DialectMySQL, DialectPostgres, DialectSQLite}
+
+// dialectAliases maps alternative spellings to a dialect
+var dialectAliases = map[string]string{
+	"postgresql": DialectPostgres,
+	"pg":         DialectPostgres,
+	"sqlite3":    DialectSQLite,
+}
+
+// ParseDialect normalizes a dialect name such as "PostgreSQL" to one of
+// Dialects. Unknown dialects are an error.
+func ParseDialect(name string) (string, error) {
+	d := strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := dialectAliases[d]; ok {
+		d = alias
+	}
+	if !InArray(Dialects, d) {
+		return "", fmt.Errorf("unknown SQL dialect %q (supported: %s)", name, strings.Join(Dialects, ", "))
+	}
+	return d, nil
+}
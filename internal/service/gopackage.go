@@ -18,6 +18,17 @@ type GoPackage struct {
 	DefaultApiRoot string `json:"default_api_root"`
 	DefaultGOOS    string `json:"default_goos"`
 	DefaultGOARCH  string `json:"default_goarch"`
+	DBDialect      string `json:"db_dialect"`
+
+	// TypeMapping overrides the Go type of SQL types in generated models,
+	// keyed by SQL type such as "decimal", "int unsigned" or "tinyint(1)"
+	TypeMapping map[string]TypeOverride `json:"type_mapping,omitempty"`
+}
+
+// TypeOverride is the Go type an SQL type maps to
+type TypeOverride struct {
+	Type   string `json:"type"`             // Go type, e.g. float64 or uuid.UUID
+	Import string `json:"import,omitempty"` // Import path of the type's package
 }
 
 var (
@@ -155,6 +166,10 @@ func ensureDefaults(gp *GoPackage) {
 	if gp.DefaultGOARCH == "" {
 		gp.DefaultGOARCH = "amd64"
 	}
+	if gp.DBDialect == "" {
+		// projects created before dialect selection use MySQL
+		gp.DBDialect = DialectMySQL
+	}
 }
 
 // exists reports whether the named file exists (and is not a directory).
@@ -227,6 +242,26 @@ func GetDefaultGOARCH() (string, error) {
 	return goPackage.DefaultGOARCH, nil
 }
 
+// GetDBDialect returns the SQL dialect of the project's lib/db connector
+func GetDBDialect() (string, error) {
+	if !goPackage.inited {
+		if err := initGoPackage(); err != nil {
+			return "", err
+		}
+	}
+	return ParseDialect(goPackage.DBDialect)
+}
+
+// GetTypeMapping returns the type_mapping section of gopackage.json
+func GetTypeMapping() (map[string]TypeOverride, error) {
+	if !goPackage.inited {
+		if err := initGoPackage(); err != nil {
+			return nil, err
+		}
+	}
+	return goPackage.TypeMapping, nil
+}
+
 func GetProjectName() (string, error) {
 	if !goPackage.inited {
 		if err := initGoPackage(); err != nil {
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HTTPMethods lists the verbs accepted by @http_method and `god gen act`
+var HTTPMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// ParseHTTPMethods parses a comma-separated list of HTTP verbs such as
+// "get, post" into upper-case, de-duplicated verbs. "ALL" is kept for
+// compatibility and expands to POST and GET. Unknown verbs are an error.
+func ParseHTTPMethods(spec string) ([]string, error) {
+	var methods []string
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.ToUpper(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+
+		expanded := []string{part}
+		if part == "ALL" {
+			expanded = []string{"POST", "GET"}
+		} else if !InArray(HTTPMethods, part) {
+			return nil, fmt.Errorf("unknown HTTP method %q (supported: %s, ALL)", part, strings.Join(HTTPMethods, ", "))
+		}
+
+		for _, m := range expanded {
+			if !InArray(methods, m) {
+				methods = append(methods, m)
+			}
+		}
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("no HTTP method in %q", spec)
+	}
+	return methods, nil
+}
@@ -0,0 +1,1034 @@
+package service
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// modelPackage is a parsed package of the model directory
+type modelPackage struct {
+	dir        string // Path relative to the model directory, "." for model itself
+	files      []*ast.File
+	names      []string // File name of each file
+	types      map[string]*ast.TypeSpec
+	tableNames map[string]string // Results of TableName methods by type name
+	embedded   map[string]bool   // Struct types inlined into other structs
+}
+
+// modelType is a model struct and the table it maps to
+type modelType struct {
+	pkg     *modelPackage
+	name    string
+	table   *Table
+	fields  map[string]string // Column of each field
+	pk      []string          // Primary key fields
+	assocs  []modelAssoc
+	indexes map[string]*modelIndex
+}
+
+// modelAssoc is a field whose type is another model struct
+type modelAssoc struct {
+	field    string
+	target   string // Key of the target in modelSchema.models
+	many     bool   // Slice of the target: has many
+	settings gormSettings
+}
+
+// modelIndex collects the columns of an index or uniqueIndex tag
+type modelIndex struct {
+	index      *Index
+	priorities []int
+}
+
+// modelSchema reads the structs of model packages with go/ast only, so
+// that the project does not have to compile
+type modelSchema struct {
+	dialect string
+	fset    *token.FileSet
+	pkgs    map[string]*modelPackage
+	models  map[string]*modelType // By package dir and type name, e.g. users.Users
+	order   []*modelType
+}
+
+// gormSetting is a key:value setting of a gorm tag, with an upper-cased key
+type gormSetting struct {
+	key   string
+	value string
+}
+
+type gormSettings []gormSetting
+
+// get returns the value of the first setting named key
+func (s gormSettings) get(key string) (string, bool) {
+	for _, setting := range s {
+		if setting.key == key {
+			return setting.value, true
+		}
+	}
+	return "", false
+}
+
+// has reports whether a setting named one of keys is present and not false
+func (s gormSettings) has(keys ...string) bool {
+	for _, key := range keys {
+		if v, ok := s.get(key); ok && !strings.EqualFold(v, "false") {
+			return true
+		}
+	}
+	return false
+}
+
+// int returns the integer value of the setting named key, or 0
+func (s gormSettings) int(key string) int {
+	v, _ := s.get(key)
+	n, _ := strconv.Atoi(strings.TrimSpace(v))
+	return n
+}
+
+// parseGormTag splits a gorm tag into settings; \; escapes a semicolon
+func parseGormTag(tag string) gormSettings {
+	var settings gormSettings
+	tag = strings.ReplaceAll(tag, `\;`, "\x00")
+	for _, part := range strings.Split(tag, ";") {
+		part = strings.ReplaceAll(part, "\x00", ";")
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, ":")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		settings = append(settings, gormSetting{key: strings.ReplaceAll(key, " ", ""), value: value})
+	}
+	return settings
+}
+
+// ParseModels reads the model structs of the packages under dir, usually
+// the model directory of a project, into a schema with the column types of
+// dialect: the reverse of GenerateModels. Structs with gorm tags are
+// models; their table is named by a TableName method or after the struct.
+func ParseModels(dir, dialect string) (*Schema, error) {
+	ms := &modelSchema{
+		dialect: dialect,
+		fset:    token.NewFileSet(),
+		pkgs:    make(map[string]*modelPackage),
+		models:  make(map[string]*modelType),
+	}
+	if err := ms.load(dir); err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+	for _, pkg := range ms.sortedPackages() {
+		for i, file := range pkg.files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok || !isModelStruct(st, file) || ms.inlinedOnly(pkg, ts.Name.Name) {
+						continue
+					}
+					doc := ts.Doc
+					if doc == nil && len(gen.Specs) == 1 {
+						doc = gen.Doc
+					}
+					mt, err := ms.newModel(pkg, file, ts.Name.Name, st, doc, strings.HasSuffix(pkg.names[i], "_gen.go"))
+					if err != nil {
+						return nil, err
+					}
+					schema.Tables = append(schema.Tables, mt.table)
+				}
+			}
+		}
+	}
+	for _, mt := range ms.order {
+		ms.foreignKeys(mt)
+	}
+	return schema, nil
+}
+
+// load parses the Go files of dir and its subdirectories
+func (ms *modelSchema) load(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(ms.fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		pkg := ms.pkgs[rel]
+		if pkg == nil {
+			pkg = &modelPackage{
+				dir:        rel,
+				types:      make(map[string]*ast.TypeSpec),
+				tableNames: make(map[string]string),
+				embedded:   make(map[string]bool),
+			}
+			ms.pkgs[rel] = pkg
+		}
+		pkg.files = append(pkg.files, file)
+		pkg.names = append(pkg.names, filepath.Base(path))
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						pkg.types[ts.Name.Name] = ts
+						markEmbedded(pkg, ts)
+					}
+				}
+			case *ast.FuncDecl:
+				if name, table, ok := tableNameMethod(decl); ok {
+					pkg.tableNames[name] = table
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (ms *modelSchema) sortedPackages() []*modelPackage {
+	dirs := make([]string, 0, len(ms.pkgs))
+	for dir := range ms.pkgs {
+		dirs = append(dirs, dir)
+	}
+	slices.Sort(dirs)
+	pkgs := make([]*modelPackage, len(dirs))
+	for i, dir := range dirs {
+		pkgs[i] = ms.pkgs[dir]
+	}
+	return pkgs
+}
+
+// tableNameMethod recognizes func (T) TableName() string { return "name" }
+func tableNameMethod(fn *ast.FuncDecl) (typeName, table string, ok bool) {
+	if fn.Name.Name != "TableName" || fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Body == nil || len(fn.Body.List) != 1 {
+		return "", "", false
+	}
+	recv := fn.Recv.List[0].Type
+	if star, isStar := recv.(*ast.StarExpr); isStar {
+		recv = star.X
+	}
+	ident, isIdent := recv.(*ast.Ident)
+	ret, isReturn := fn.Body.List[0].(*ast.ReturnStmt)
+	if !isIdent || !isReturn || len(ret.Results) != 1 {
+		return "", "", false
+	}
+	lit, isLit := ret.Results[0].(*ast.BasicLit)
+	if !isLit || lit.Kind != token.STRING {
+		return "", "", false
+	}
+	table, err := strconv.Unquote(lit.Value)
+	return ident.Name, table, err == nil
+}
+
+// markEmbedded records the local struct types the fields of ts inline
+func markEmbedded(pkg *modelPackage, ts *ast.TypeSpec) {
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return
+	}
+	for _, f := range st.Fields.List {
+		tag, _ := gormTag(f)
+		if len(f.Names) > 0 && !parseGormTag(tag).has("EMBEDDED") {
+			continue
+		}
+		expr := f.Type
+		if star, ok := expr.(*ast.StarExpr); ok {
+			expr = star.X
+		}
+		if ident, ok := expr.(*ast.Ident); ok {
+			pkg.embedded[ident.Name] = true
+		}
+	}
+}
+
+// inlinedOnly reports whether a struct only exists to be inlined into other
+// models: it is embedded somewhere and has no TableName of its own
+func (ms *modelSchema) inlinedOnly(pkg *modelPackage, name string) bool {
+	_, named := pkg.tableNames[name]
+	return pkg.embedded[name] && !named
+}
+
+// isModelStruct reports whether a struct has gorm tags or embeds gorm.Model
+func isModelStruct(st *ast.StructType, file *ast.File) bool {
+	for _, f := range st.Fields.List {
+		if _, ok := gormTag(f); ok {
+			return true
+		}
+		if len(f.Names) == 0 && isGormModel(f.Type, file) {
+			return true
+		}
+	}
+	return false
+}
+
+func isGormModel(expr ast.Expr, file *ast.File) bool {
+	path, name := selectorType(expr, file)
+	return path == "gorm.io/gorm" && name == "Model"
+}
+
+// gormTag returns the gorm tag of a field
+func gormTag(f *ast.Field) (string, bool) {
+	if f.Tag == nil {
+		return "", false
+	}
+	tag, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return "", false
+	}
+	return reflect.StructTag(tag).Lookup("gorm")
+}
+
+// newModel maps a model struct to a table. Comments of generated files are
+// the table and column comments, see GenerateModels.
+func (ms *modelSchema) newModel(pkg *modelPackage, file *ast.File, name string, st *ast.StructType, doc *ast.CommentGroup, generated bool) (*modelType, error) {
+	tableName, ok := pkg.tableNames[name]
+	if !ok {
+		tableName = toDBName(name)
+	}
+	mt := &modelType{
+		pkg:     pkg,
+		name:    name,
+		table:   &Table{Name: tableName},
+		fields:  make(map[string]string),
+		indexes: make(map[string]*modelIndex),
+	}
+	if generated && doc != nil {
+		mt.table.Comment = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(doc.Text()), name))
+	}
+	if err := ms.addFields(mt, file, st, "", generated, 0); err != nil {
+		return nil, err
+	}
+	for _, mi := range mt.indexes {
+		// stable sort of the columns by priority
+		idx := mi.index
+		order := make([]int, len(idx.Columns))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortStableFunc(order, func(a, b int) int { return mi.priorities[a] - mi.priorities[b] })
+		columns := make([]string, len(order))
+		for i, j := range order {
+			columns[i] = idx.Columns[j]
+		}
+		idx.Columns = columns
+	}
+	ms.models[pkg.dir+"."+name] = mt
+	ms.order = append(ms.order, mt)
+	return mt, nil
+}
+
+// addFields adds the columns of the fields of st, inlining embedded structs
+func (ms *modelSchema) addFields(mt *modelType, file *ast.File, st *ast.StructType, prefix string, generated bool, depth int) error {
+	if depth > 8 {
+		return fmt.Errorf("%s: struct %s embeds itself", ms.fset.Position(st.Pos()), mt.name)
+	}
+	for _, f := range st.Fields.List {
+		tag, _ := gormTag(f)
+		settings := parseGormTag(tag)
+		if _, ok := settings.get("-"); ok {
+			// -, -:all and -:migration all keep the field out of the table
+			continue
+		}
+
+		if len(f.Names) == 0 || settings.has("EMBEDDED") {
+			if len(f.Names) == 0 && isGormModel(f.Type, file) {
+				ms.addGormModel(mt, prefix)
+				continue
+			}
+			embedded, ok := ms.localStruct(mt.pkg, f.Type)
+			if !ok {
+				return fmt.Errorf("%s: cannot inline embedded field %s of %s", ms.fset.Position(f.Pos()), exprString(f.Type), mt.name)
+			}
+			embeddedPrefix, _ := settings.get("EMBEDDEDPREFIX")
+			if err := ms.addFields(mt, file, embedded, prefix+embeddedPrefix, generated, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, name := range f.Names {
+			if !name.IsExported() {
+				continue
+			}
+			if err := ms.addField(mt, file, f, name.Name, settings, prefix, generated); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addField adds the column of a struct field, or records the association
+// a field of a model type stands for
+func (ms *modelSchema) addField(mt *modelType, file *ast.File, f *ast.Field, name string, settings gormSettings, prefix string, generated bool) error {
+	kind := ms.goKind(mt.pkg, file, f.Type, 0)
+	if _, ok := settings.get("SERIALIZER"); ok {
+		kind = "json"
+	}
+	sqlType, hasType := settings.get("TYPE")
+	if kind == "" && !hasType {
+		if target, many, ok := ms.modelTarget(mt.pkg, file, f.Type); ok {
+			mt.assocs = append(mt.assocs, modelAssoc{field: name, target: target, many: many, settings: settings})
+			return nil
+		}
+		return fmt.Errorf("%s: cannot map type %s of %s.%s to a column; add a gorm type tag, or gorm:\"-\" to skip it",
+			ms.fset.Position(f.Pos()), exprString(f.Type), mt.name, name)
+	}
+
+	column, ok := settings.get("COLUMN")
+	if !ok {
+		column = prefix + toDBName(name)
+	}
+	col := &Column{
+		Name:          column,
+		NotNull:       settings.has("NOTNULL", "NOT NULL"),
+		AutoIncrement: settings.has("AUTOINCREMENT"),
+		Unique:        settings.has("UNIQUE"),
+	}
+	primaryKey := settings.has("PRIMARYKEY", "PRIMARY_KEY")
+	if comment, ok := settings.get("COMMENT"); ok {
+		col.Comment = comment
+	} else if generated && f.Doc != nil {
+		col.Comment = strings.TrimSpace(f.Doc.Text())
+	}
+	if value, ok := settings.get("DEFAULT"); ok {
+		col.HasDefault = true
+		col.Default, col.DefaultQuoted = defaultValue(value, kind)
+	}
+
+	keyed := primaryKey || col.Unique || col.HasDefault
+	for _, s := range settings {
+		if s.key != "INDEX" && s.key != "UNIQUEINDEX" {
+			continue
+		}
+		keyed = true
+		mt.addIndex(column, s)
+	}
+	if hasType {
+		if i := strings.Index(strings.ToLower(sqlType), " on update "); i >= 0 {
+			sqlType, col.OnUpdate = strings.TrimSpace(sqlType[:i]), strings.ToLower(strings.TrimSpace(sqlType[i+len(" on update "):]))
+		}
+		if !translateType(col, sqlType, ms.dialect) {
+			return fmt.Errorf("%s: type %s of %s.%s has no %s equivalent; change the gorm type tag",
+				ms.fset.Position(f.Pos()), sqlType, mt.name, name, ms.dialect)
+		}
+		if ms.dialect == DialectMySQL && settings.has("UNSIGNED") && !strings.Contains(col.Type, "unsigned") {
+			col.Type += " unsigned"
+		}
+	} else if col.Type = modelColumnType(kind, settings, ms.dialect, keyed, col.AutoIncrement); col.Type == "" {
+		return fmt.Errorf("%s: %s has no %s column type for %s.%s; add a gorm type tag",
+			ms.fset.Position(f.Pos()), exprString(f.Type), ms.dialect, mt.name, name)
+	} else {
+		booleanDefault(col)
+	}
+
+	mt.table.Columns = append(mt.table.Columns, col)
+	mt.fields[name] = column
+	if primaryKey {
+		mt.pk = append(mt.pk, name)
+		mt.table.PrimaryKey = append(mt.table.PrimaryKey, column)
+	}
+	return nil
+}
+
+// addGormModel adds the columns of an embedded gorm.Model
+func (ms *modelSchema) addGormModel(mt *modelType, prefix string) {
+	id := &Column{Name: prefix + "id", AutoIncrement: true,
+		Type: modelColumnType("uint64", nil, ms.dialect, true, true)}
+	mt.table.Columns = append(mt.table.Columns, id)
+	mt.table.PrimaryKey = append(mt.table.PrimaryKey, id.Name)
+	mt.fields["ID"] = id.Name
+	mt.pk = append(mt.pk, "ID")
+	for _, name := range []string{"CreatedAt", "UpdatedAt", "DeletedAt"} {
+		col := &Column{Name: prefix + toDBName(name), Type: modelColumnType("time", nil, ms.dialect, false, false)}
+		mt.table.Columns = append(mt.table.Columns, col)
+		mt.fields[name] = col.Name
+	}
+	mt.addIndex(prefix+"deleted_at", gormSetting{key: "INDEX"})
+}
+
+// addIndex adds column to the index an index or uniqueIndex setting
+// declares: [name][,priority:n][,class:FULLTEXT][,type:btree][,unique]
+func (mt *modelType) addIndex(column string, s gormSetting) {
+	options := strings.Split(s.value, ",")
+	name := strings.TrimSpace(options[0])
+	if name == "" {
+		name = "idx_" + mt.table.Name + "_" + column
+	}
+	mi := mt.indexes[name]
+	if mi == nil {
+		mi = &modelIndex{index: &Index{Name: name, Unique: s.key == "UNIQUEINDEX"}}
+		mt.indexes[name] = mi
+		mt.table.Indexes = append(mt.table.Indexes, mi.index)
+	}
+	priority := 10
+	for _, opt := range options[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), ":")
+		switch strings.ToLower(key) {
+		case "priority":
+			if p, err := strconv.Atoi(value); err == nil {
+				priority = p
+			}
+		case "class":
+			mi.index.Class = strings.ToUpper(value)
+		case "type", "using":
+			mi.index.Method = strings.ToLower(value)
+		case "unique":
+			mi.index.Unique = true
+		}
+	}
+	mi.index.Columns = append(mi.index.Columns, column)
+	mi.priorities = append(mi.priorities, priority)
+}
+
+// constraintName matches the constraint names gorm accepts in a
+// constraint setting
+var constraintName = regexp.MustCompile(`^[\w-]+$`)
+
+// foreignKeys turns the associations of mt into foreign keys: belongs-to
+// when the foreign key fields are on mt, has-one or has-many when they are
+// on the target. Without a foreignKey setting gorm's defaults apply, e.g.
+// UserID for a User field.
+func (ms *modelSchema) foreignKeys(mt *modelType) {
+	for _, a := range mt.assocs {
+		target := ms.models[a.target]
+		if target == nil {
+			continue
+		}
+		var fkFields []string
+		if v, ok := a.settings.get("FOREIGNKEY"); ok {
+			fkFields = splitList(v)
+		}
+		var refFields []string
+		if v, ok := a.settings.get("REFERENCES"); ok {
+			refFields = splitList(v)
+		}
+
+		owner, referenced := mt, target // belongs to
+		if fkFields == nil {
+			if !a.many {
+				fkFields = prefixed(a.field, target.pk)
+			}
+			if a.many || !hasFields(mt, fkFields) {
+				fkFields = prefixed(mt.name, mt.pk)
+			}
+		}
+		if a.many || !hasFields(mt, fkFields) {
+			owner, referenced = target, mt // has one or has many
+		}
+		if refFields == nil {
+			refFields = referenced.pk
+		}
+		if len(fkFields) == 0 || len(fkFields) != len(refFields) || !hasFields(owner, fkFields) || !hasFields(referenced, refFields) {
+			continue
+		}
+
+		fk := &ForeignKey{Name: "fk_" + mt.table.Name + "_" + toDBName(a.field), Reference: Reference{Table: referenced.table.Name}}
+		for i := range fkFields {
+			fk.Columns = append(fk.Columns, owner.fields[fkFields[i]])
+			fk.Reference.Columns = append(fk.Reference.Columns, referenced.fields[refFields[i]])
+		}
+		if v, ok := a.settings.get("CONSTRAINT"); ok {
+			// like gorm, a name comes first and is followed by a comma
+			if name, _, ok := strings.Cut(v, ","); ok && constraintName.MatchString(name) {
+				fk.Name = name
+			}
+			for _, action := range strings.Split(v, ",") {
+				key, value, _ := strings.Cut(action, ":")
+				switch strings.ToUpper(strings.TrimSpace(key)) {
+				case "ONDELETE":
+					fk.OnDelete = strings.ToUpper(strings.TrimSpace(value))
+				case "ONUPDATE":
+					fk.OnUpdate = strings.ToUpper(strings.TrimSpace(value))
+				}
+			}
+		}
+		duplicate := slices.ContainsFunc(owner.table.ForeignKeys, func(other *ForeignKey) bool {
+			return strings.EqualFold(other.Table, fk.Table) && slices.Equal(other.Columns, fk.Columns)
+		})
+		if !duplicate {
+			owner.table.ForeignKeys = append(owner.table.ForeignKeys, fk)
+		}
+	}
+}
+
+func hasFields(mt *modelType, fields []string) bool {
+	for _, f := range fields {
+		if _, ok := mt.fields[f]; !ok {
+			return false
+		}
+	}
+	return len(fields) > 0
+}
+
+func prefixed(prefix string, names []string) []string {
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = prefix + n
+	}
+	return out
+}
+
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// goKind classifies a field type by the column it maps to: bool, int8 to
+// uint64, float32, float64, string, bytes, time, decimal, json, uuid or
+// array:<element kind> for pq arrays. It returns "" for other types.
+func (ms *modelSchema) goKind(pkg *modelPackage, file *ast.File, expr ast.Expr, depth int) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return ms.goKind(pkg, file, t.X, depth)
+	case *ast.ArrayType:
+		if t.Len == nil && (exprString(t.Elt) == "byte" || exprString(t.Elt) == "uint8") {
+			return "bytes"
+		}
+	case *ast.IndexExpr:
+		// sql.Null[T]
+		if path, name := selectorType(t.X, file); path == "database/sql" && name == "Null" {
+			return ms.goKind(pkg, file, t.Index, depth)
+		}
+	case *ast.Ident:
+		switch t.Name {
+		case "bool", "string", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+			return t.Name
+		case "int":
+			return "int64"
+		case "uint":
+			return "uint64"
+		case "byte":
+			return "uint8"
+		case "rune":
+			return "int32"
+		}
+		// named types such as generated enums
+		if ts, ok := pkg.types[t.Name]; ok && depth < 8 {
+			if _, isStruct := ts.Type.(*ast.StructType); !isStruct {
+				return ms.goKind(pkg, file, ts.Type, depth+1)
+			}
+		}
+	case *ast.SelectorExpr:
+		path, name := selectorType(t, file)
+		switch {
+		case path == "time" && name == "Time", path == "gorm.io/gorm" && name == "DeletedAt",
+			strings.HasSuffix(path, "/lib/mytime") && (name == "DateTime" || name == "NullDateTime"):
+			return "time"
+		case path == "database/sql":
+			return sqlNullKinds[name]
+		case path == "github.com/shopspring/decimal" && (name == "Decimal" || name == "NullDecimal"):
+			return "decimal"
+		case path == "encoding/json" && name == "RawMessage", path == "gorm.io/datatypes" && name == "JSON":
+			return "json"
+		case path == "github.com/google/uuid" && name == "UUID":
+			return "uuid"
+		case path == "github.com/lib/pq":
+			if elem, ok := pqArrayKinds[name]; ok {
+				return "array:" + elem
+			}
+		}
+	}
+	return ""
+}
+
+// sqlNullKinds maps database/sql null types to their value kind
+var sqlNullKinds = map[string]string{
+	"NullBool":    "bool",
+	"NullByte":    "uint8",
+	"NullInt16":   "int16",
+	"NullInt32":   "int32",
+	"NullInt64":   "int64",
+	"NullFloat64": "float64",
+	"NullString":  "string",
+	"NullTime":    "time",
+}
+
+// pqArrayKinds maps pq array types to their element kind
+var pqArrayKinds = map[string]string{
+	"BoolArray":    "bool",
+	"ByteaArray":   "bytes",
+	"Float32Array": "float32",
+	"Float64Array": "float64",
+	"Int32Array":   "int32",
+	"Int64Array":   "int64",
+	"StringArray":  "string",
+}
+
+// modelTarget resolves a field type such as *users.Users or []Post to the
+// model struct it refers to
+func (ms *modelSchema) modelTarget(pkg *modelPackage, file *ast.File, expr ast.Expr) (key string, many bool, ok bool) {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+			continue
+		case *ast.ArrayType:
+			expr, many = t.Elt, true
+			continue
+		case *ast.Ident:
+			key = pkg.dir + "." + t.Name
+		case *ast.SelectorExpr:
+			path, name := selectorType(t, file)
+			// example.com/app/model/users -> users
+			i := strings.LastIndex(path, "/model/")
+			if i < 0 {
+				return "", false, false
+			}
+			key = path[i+len("/model/"):] + "." + name
+		default:
+			return "", false, false
+		}
+		_, ok = ms.models[key]
+		if !ok {
+			// the target may be declared in a package not walked yet
+			dir, name, _ := strings.Cut(key, ".")
+			if p := ms.pkgs[dir]; p != nil {
+				if ts, found := p.types[name]; found {
+					_, ok = ts.Type.(*ast.StructType)
+				}
+			}
+		}
+		return key, many, ok
+	}
+}
+
+// localStruct returns the struct type named by an embedded field declared
+// in the same package
+func (ms *modelSchema) localStruct(pkg *modelPackage, expr ast.Expr) (*ast.StructType, bool) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+	ts, ok := pkg.types[ident.Name]
+	if !ok {
+		return nil, false
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	return st, ok
+}
+
+// selectorType resolves pkg.Name to the import path of pkg and Name
+func selectorType(expr ast.Expr, file *ast.File) (path, name string) {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return "", ""
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", ""
+	}
+	for _, imp := range file.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		local := p[strings.LastIndex(p, "/")+1:]
+		if imp.Name != nil {
+			local = imp.Name.Name
+		}
+		if local == x.Name {
+			return p, sel.Sel.Name
+		}
+	}
+	return "", sel.Sel.Name
+}
+
+// modelColumnType returns the column type of a field kind in dialect, or
+// "" when the dialect has none. keyed strings are indexed or defaulted and
+// need a bounded MySQL type, as with gorm.
+func modelColumnType(kind string, settings gormSettings, dialect string, keyed, autoIncrement bool) string {
+	size, precision, scale := settings.int("SIZE"), settings.int("PRECISION"), settings.int("SCALE")
+
+	if elem, ok := strings.CutPrefix(kind, "array:"); ok {
+		if dialect != DialectPostgres {
+			return ""
+		}
+		return modelColumnType(elem, nil, dialect, false, false) + "[]"
+	}
+	switch kind {
+	case "bool":
+		return map[string]string{DialectMySQL: "tinyint(1)", DialectPostgres: "boolean", DialectSQLite: "boolean"}[dialect]
+	case "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64":
+		unsigned := strings.HasPrefix(kind, "u")
+		bits := strings.TrimLeft(kind, "uint")
+		switch dialect {
+		case DialectMySQL:
+			t := map[string]string{"8": "tinyint", "16": "smallint", "32": "int", "64": "bigint"}[bits]
+			if unsigned || settings.has("UNSIGNED") {
+				t += " unsigned"
+			}
+			return t
+		case DialectPostgres:
+			// unsigned values need the next wider type
+			width := map[string]int{"8": 16, "16": 16, "32": 32, "64": 64}[bits]
+			if unsigned && width < 64 && bits != "8" {
+				width *= 2
+			}
+			if autoIncrement {
+				return map[int]string{16: "smallserial", 32: "serial", 64: "bigserial"}[width]
+			}
+			return map[int]string{16: "smallint", 32: "integer", 64: "bigint"}[width]
+		default:
+			return "integer"
+		}
+	case "float32":
+		return map[string]string{DialectMySQL: "float", DialectPostgres: "real", DialectSQLite: "real"}[dialect]
+	case "float64":
+		return map[string]string{DialectMySQL: "double", DialectPostgres: "double precision", DialectSQLite: "real"}[dialect]
+	case "decimal":
+		t := map[string]string{DialectMySQL: "decimal", DialectPostgres: "numeric", DialectSQLite: "decimal"}[dialect]
+		if precision > 0 {
+			t += fmt.Sprintf("(%d,%d)", precision, scale)
+		}
+		return t
+	case "string":
+		switch {
+		case dialect == DialectSQLite:
+			return "text"
+		case size > 0 && (dialect == DialectPostgres || size < 65536):
+			return fmt.Sprintf("varchar(%d)", size)
+		case dialect == DialectPostgres:
+			return "text"
+		case size >= 65536 && size < 1<<24:
+			return "mediumtext"
+		case size == 0 && keyed:
+			return "varchar(191)"
+		default:
+			return "longtext"
+		}
+	case "bytes":
+		switch {
+		case dialect == DialectPostgres:
+			return "bytea"
+		case dialect == DialectSQLite:
+			return "blob"
+		case size > 0 && size < 65536:
+			return fmt.Sprintf("varbinary(%d)", size)
+		default:
+			return "longblob"
+		}
+	case "time":
+		t := map[string]string{DialectMySQL: "datetime", DialectPostgres: "timestamp", DialectSQLite: "datetime"}[dialect]
+		if precision > 0 && dialect != DialectSQLite {
+			t += fmt.Sprintf("(%d)", precision)
+		}
+		return t
+	case "json":
+		return map[string]string{DialectMySQL: "json", DialectPostgres: "jsonb", DialectSQLite: "text"}[dialect]
+	case "uuid":
+		return map[string]string{DialectMySQL: "char(36)", DialectPostgres: "uuid", DialectSQLite: "text"}[dialect]
+	}
+	return ""
+}
+
+// dialectTypes maps column types written for other databases to the type
+// of a dialect. An empty type has no equivalent.
+var dialectTypes = map[string]map[string]string{
+	DialectMySQL: {
+		"jsonb": "json", "bytea": "longblob", "uuid": "char(36)", "citext": "text",
+		"int2": "smallint", "int4": "int", "int8": "bigint", "float4": "float", "float8": "double",
+		"smallserial": "smallint", "serial": "int", "bigserial": "bigint",
+		"character varying": "varchar", "timestamptz": "timestamp",
+		"timestamp with time zone": "timestamp", "timestamp without time zone": "timestamp",
+		"inet": "", "cidr": "", "macaddr": "", "interval": "", "tsvector": "", "tsquery": "", "hstore": "", "xml": "", "money": "",
+	},
+	DialectPostgres: {
+		"tinyint": "smallint", "smallint": "smallint", "mediumint": "integer", "int": "integer", "integer": "integer", "bigint": "bigint",
+		"year": "smallint", "datetime": "timestamp", "double": "double precision", "float": "real",
+		"tinytext": "text", "mediumtext": "text", "longtext": "text",
+		"binary": "bytea", "varbinary": "bytea", "tinyblob": "bytea", "blob": "bytea", "mediumblob": "bytea", "longblob": "bytea",
+	},
+	DialectSQLite: {
+		"json": "text", "jsonb": "text", "uuid": "text", "bytea": "blob",
+		"smallserial": "integer", "serial": "integer", "bigserial": "integer",
+	},
+}
+
+// sizedTypes keep the size or precision of the type they are mapped from
+var sizedTypes = []string{"varchar", "char", "decimal", "numeric", "timestamp", "time", "datetime"}
+
+// widerTypes holds the Postgres type of unsigned integer types
+var widerTypes = map[string]string{"tinyint": "smallint", "smallint": "integer", "mediumint": "integer", "int": "bigint", "integer": "bigint"}
+
+// translateType sets the type of col from a gorm type setting, which may be
+// written for another database. ENUM types become text checked against
+// their members outside MySQL, SET types plain text. It reports false when
+// dialect has no equivalent type.
+func translateType(col *Column, sqlType, dialect string) bool {
+	toks, err := lexDDL("", dialect, sqlType)
+	if err != nil {
+		return false
+	}
+	toks = toks[:len(toks)-1] // EOF
+	if len(toks) == 0 || toks[0].kind != tokIdent {
+		return false
+	}
+	col.Values = typeValues(toks)
+	if col.Values != nil {
+		switch {
+		case dialect == DialectMySQL:
+			col.Type = renderTokens(toks, true)
+		case isKeyword(toks[0], "SET"):
+			col.Type = "text"
+		case dialect == DialectPostgres:
+			longest := 1
+			for _, v := range col.Values {
+				longest = max(longest, len(v))
+			}
+			col.Type, col.CheckValues = fmt.Sprintf("varchar(%d)", longest), true
+		default:
+			col.Type, col.CheckValues = "text", true
+		}
+		return true
+	}
+
+	// the type name may span several words
+	n := 1
+	for n < len(toks) && toks[n].kind == tokIdent && !isKeyword(toks[n], "UNSIGNED", "ZEROFILL", "SIGNED") {
+		n++
+	}
+	base := renderTokens(toks[:n], true)
+	rest := toks[n:]
+	var args []ddlToken
+	if len(rest) > 0 && isPunct(rest[0], "(") {
+		end := slices.IndexFunc(rest, func(t ddlToken) bool { return isPunct(t, ")") })
+		if end < 0 {
+			return false
+		}
+		args, rest = rest[:end+1], rest[end+1:]
+	}
+	if dialect != DialectPostgres && slices.ContainsFunc(rest, func(t ddlToken) bool { return isPunct(t, "[") }) {
+		return false // arrays
+	}
+	if dialect != DialectMySQL {
+		unsigned := slices.ContainsFunc(rest, func(t ddlToken) bool { return isKeyword(t, "UNSIGNED") })
+		rest = slices.DeleteFunc(slices.Clone(rest), func(t ddlToken) bool { return isKeyword(t, "UNSIGNED", "ZEROFILL", "SIGNED") })
+		if unsigned && dialect == DialectPostgres && widerTypes[base] != "" {
+			base = widerTypes[base]
+		}
+		if base == "tinyint" && renderTokens(args, true) == "(1)" {
+			base, args = "boolean", nil
+		}
+	}
+	if to, ok := dialectTypes[dialect][base]; ok {
+		if to == "" {
+			return false
+		}
+		base = to
+		if !slices.Contains(sizedTypes, to) {
+			args = nil
+		}
+	}
+	typ := append([]ddlToken{{kind: tokIdent, text: base}}, args...)
+	col.Type = renderTokens(append(typ, rest...), true)
+	booleanDefault(col)
+	return true
+}
+
+// booleanDefault writes a 0 or 1 default of a boolean column as false or
+// true, which Postgres requires
+func booleanDefault(col *Column) {
+	if col.Type != "boolean" || !col.HasDefault {
+		return
+	}
+	switch col.Default {
+	case "0":
+		col.Default, col.DefaultQuoted = "false", false
+	case "1":
+		col.Default, col.DefaultQuoted = "true", false
+	}
+}
+
+// defaultValue interprets a default setting for a field kind: strings are
+// quoted unless they are a function call, numbers and booleans of numeric
+// fields and words such as current_timestamp are written as is
+func defaultValue(value, kind string) (string, bool) {
+	trimmed := strings.TrimSpace(value)
+	_, numErr := strconv.ParseFloat(trimmed, 64)
+	switch {
+	case len(trimmed) >= 2 && trimmed[0] == '\'' && trimmed[len(trimmed)-1] == '\'':
+		return strings.ReplaceAll(trimmed[1:len(trimmed)-1], "''", "'"), true
+	case strings.EqualFold(trimmed, "null"):
+		return "NULL", false
+	case strings.HasSuffix(trimmed, ")") && strings.Contains(trimmed, "("):
+		return trimmed, false
+	case kind == "string" || kind == "uuid" || kind == "json" || kind == "bytes":
+		return value, true
+	case numErr == nil, strings.EqualFold(trimmed, "true"), strings.EqualFold(trimmed, "false"):
+		return trimmed, false
+	case kind == "time" && trimmed != "" && strings.IndexFunc(trimmed, func(r rune) bool {
+		return r != '_' && !unicode.IsLetter(r)
+	}) < 0:
+		return trimmed, false
+	}
+	return value, true
+}
+
+// toDBName converts a Go name to a column or table name the way gorm's
+// naming strategy does: UserID -> user_id, HTTPServer -> http_server
+func toDBName(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// exprString renders a type expression for messages
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.ArrayType:
+		return "[]" + exprString(t.Elt)
+	case *ast.MapType:
+		return "map[" + exprString(t.Key) + "]" + exprString(t.Value)
+	case *ast.IndexExpr:
+		return exprString(t.X) + "[" + exprString(t.Index) + "]"
+	}
+	return fmt.Sprintf("%T", expr)
+}
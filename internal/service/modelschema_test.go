@@ -0,0 +1,46 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTranslateType(t *testing.T) {
+	tests := []struct {
+		sqlType string
+		dialect string
+		def     string
+		want    Column // Type, Values, CheckValues and Default; zero Type when untranslatable
+	}{
+		{"VARCHAR(64)", DialectPostgres, "", Column{Type: "varchar(64)"}},
+		{"enum('a','bc')", DialectMySQL, "", Column{Type: "enum('a','bc')", Values: []string{"a", "bc"}}},
+		{"enum('a','bc')", DialectPostgres, "", Column{Type: "varchar(2)", Values: []string{"a", "bc"}, CheckValues: true}},
+		{"enum('a','bc')", DialectSQLite, "", Column{Type: "text", Values: []string{"a", "bc"}, CheckValues: true}},
+		{"set('a','b')", DialectPostgres, "", Column{Type: "text", Values: []string{"a", "b"}}},
+		{"jsonb", DialectMySQL, "", Column{Type: "json"}},
+		{"jsonb", DialectSQLite, "", Column{Type: "text"}},
+		{"datetime(3)", DialectPostgres, "", Column{Type: "timestamp(3)"}},
+		{"int(11) unsigned", DialectPostgres, "", Column{Type: "bigint"}},
+		{"int(11) unsigned", DialectMySQL, "", Column{Type: "int(11) unsigned"}},
+		{"tinyint(1)", DialectPostgres, "", Column{Type: "boolean"}},
+		{"tinyint(1)", DialectPostgres, "0", Column{Type: "boolean", HasDefault: true, Default: "false"}},
+		{"tinyint(1)", DialectSQLite, "1", Column{Type: "boolean", HasDefault: true, Default: "true"}},
+		{"tinyint(1)", DialectMySQL, "1", Column{Type: "tinyint(1)", HasDefault: true, Default: "1"}},
+		{"tinyint(2)", DialectPostgres, "1", Column{Type: "smallint", HasDefault: true, Default: "1"}},
+		{"longblob", DialectPostgres, "", Column{Type: "bytea"}},
+		{"timestamp with time zone", DialectMySQL, "", Column{Type: "timestamp"}},
+		{"text[]", DialectPostgres, "", Column{Type: "text[]"}},
+		{"text[]", DialectMySQL, "", Column{}},
+		{"inet", DialectMySQL, "", Column{}},
+	}
+	for _, tt := range tests {
+		got := Column{HasDefault: tt.def != "", Default: tt.def}
+		if ok := translateType(&got, tt.sqlType, tt.dialect); ok != (tt.want.Type != "") {
+			t.Errorf("translateType(%q, %s) ok = %v", tt.sqlType, tt.dialect, ok)
+			continue
+		}
+		if tt.want.Type != "" && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("translateType(%q, %s)\n got %+v\nwant %+v", tt.sqlType, tt.dialect, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,458 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// MigrationSQL returns the statements that turn the from schema into the to
+// schema in dialect, or an empty string when they are the same. Tables and
+// columns are matched by name, so a rename shows up as a drop and an add.
+// Swapping the arguments yields the down migration.
+func MigrationSQL(from, to *Schema, dialect string) string {
+	var added, removed []*Table
+	var changed [][2]*Table
+	for _, t := range to.Tables {
+		if old := from.Lookup(t.Name); old != nil {
+			changed = append(changed, [2]*Table{old, t})
+		} else {
+			added = append(added, t)
+		}
+	}
+	for _, t := range from.Tables {
+		if to.Lookup(t.Name) == nil {
+			removed = append(removed, t)
+		}
+	}
+
+	alters := make([]*tableDiff, 0, len(changed))
+	for _, pair := range changed {
+		alters = append(alters, diffTable(from, to, pair[0], pair[1], dialect))
+	}
+	// Foreign keys go first and come back last, so that the tables and
+	// columns they point at can change in between
+	var stmts []string
+	for _, d := range alters {
+		stmts = append(stmts, d.dropKeys...)
+	}
+	dropped := creationOrder(&Schema{Tables: removed}, dialect)
+	for i := len(dropped) - 1; i >= 0; i-- {
+		stmts = append(stmts, "DROP TABLE "+QuoteIdent(dropped[i].Name, dialect)+";")
+	}
+	for _, d := range alters {
+		stmts = append(stmts, d.alter...)
+	}
+	if len(added) > 0 {
+		stmts = append(stmts, strings.TrimSuffix(WriteDDL(&Schema{Tables: added}, dialect), "\n"))
+	}
+	for _, d := range alters {
+		stmts = append(stmts, d.addKeys...)
+	}
+	if len(stmts) == 0 {
+		return ""
+	}
+	return strings.Join(stmts, "\n") + "\n"
+}
+
+// tableDiff holds the statements that change one table
+type tableDiff struct {
+	table    *Table
+	dialect  string
+	dropKeys []string // Foreign keys to drop
+	alter    []string // Columns, primary key, indexes and comment
+	addKeys  []string // Foreign keys to add
+}
+
+func (d *tableDiff) alterf(format string, args ...any) {
+	d.alter = append(d.alter, fmt.Sprintf("ALTER TABLE %s ", QuoteIdent(d.table.Name, d.dialect))+fmt.Sprintf(format, args...)+";")
+}
+
+// diffTable compares two versions of a table. SQLite cannot change columns
+// or constraints in place, so such changes rebuild the table there.
+func diffTable(fromSchema, toSchema *Schema, from, to *Table, dialect string) *tableDiff {
+	d := &tableDiff{table: to, dialect: dialect}
+
+	var addCols, dropCols []*Column
+	var modified [][2]*Column
+	for _, col := range to.Columns {
+		if i := from.columnIndex(col.Name); i < 0 {
+			addCols = append(addCols, col)
+		} else if !sameColumn(from, to, from.Columns[i], col, dialect) {
+			modified = append(modified, [2]*Column{from.Columns[i], col})
+		}
+	}
+	for _, col := range from.Columns {
+		if to.columnIndex(col.Name) < 0 {
+			dropCols = append(dropCols, col)
+		}
+	}
+	oldPK, newPK := primaryKeyColumns(from), primaryKeyColumns(to)
+	pkChanged := !slices.Equal(oldPK, newPK)
+	dropFKs, addFKs := diffForeignKeys(fromSchema, toSchema, from, to)
+	dropIdx, addIdx := diffIndexes(from, to)
+
+	if dialect == DialectSQLite {
+		rebuild := len(modified) > 0 || pkChanged || len(dropFKs) > 0 || len(addFKs) > 0
+		for _, col := range addCols {
+			// ADD COLUMN takes neither keys nor NOT NULL without a default
+			if col.PrimaryKey || col.Unique || col.References != nil || (col.NotNull && !col.HasDefault) {
+				rebuild = true
+			}
+		}
+		if rebuild {
+			d.alter = sqliteRebuild(from, to)
+			return d
+		}
+	}
+
+	for _, fk := range dropFKs {
+		d.dropKeys = append(d.dropKeys, dropForeignKeySQL(from, fk, dialect))
+	}
+	for _, idx := range dropIdx {
+		d.alter = append(d.alter, dropIndexSQL(from, idx, dialect))
+	}
+	if pkChanged && len(oldPK) > 0 {
+		if dialect == DialectMySQL {
+			d.alterf("DROP PRIMARY KEY")
+		} else {
+			d.alterf("DROP CONSTRAINT %s", QuoteIdent(from.Name+"_pkey", dialect))
+		}
+	}
+	for _, col := range dropCols {
+		d.alterf("DROP COLUMN %s", QuoteIdent(col.Name, dialect))
+	}
+	for _, col := range addCols {
+		def := ColumnSQL(withoutKey(col), dialect)
+		if dialect == DialectMySQL {
+			if i := to.columnIndex(col.Name); i == 0 {
+				def += " FIRST"
+			} else {
+				def += " AFTER " + QuoteIdent(to.Columns[i-1].Name, dialect)
+			}
+		}
+		d.alterf("ADD COLUMN %s", def)
+		if dialect == DialectPostgres && col.Comment != "" {
+			d.alter = append(d.alter, commentOnColumn(to, col))
+		}
+	}
+	for _, pair := range modified {
+		d.modifyColumn(from, pair[0], pair[1])
+	}
+	if pkChanged && len(newPK) > 0 {
+		d.alterf("ADD PRIMARY KEY (%s)", quoteColumns(to, newPK, dialect))
+	}
+	for _, idx := range addIdx {
+		if dialect == DialectMySQL {
+			d.alterf("ADD %s", mysqlIndexSQL(to, idx))
+		} else {
+			d.alter = append(d.alter, CreateIndexSQL(to, idx, dialect)+";")
+		}
+	}
+	if from.Comment != to.Comment {
+		switch dialect {
+		case DialectMySQL:
+			d.alterf("COMMENT = %s", quoteString(to.Comment))
+		case DialectPostgres:
+			d.alter = append(d.alter, fmt.Sprintf("COMMENT ON TABLE %s IS %s;", QuoteIdent(to.Name, dialect), commentLiteral(to.Comment)))
+		}
+	}
+	for _, fk := range addFKs {
+		d.addKeys = append(d.addKeys, fmt.Sprintf("ALTER TABLE %s ADD %s;", QuoteIdent(to.Name, dialect), ForeignKeySQL(to, fk, dialect)))
+	}
+	return d
+}
+
+// modifyColumn changes a column to its new definition: MODIFY COLUMN on
+// MySQL, one ALTER COLUMN per changed property on Postgres
+func (d *tableDiff) modifyColumn(fromTable *Table, from, to *Column) {
+	dialect := d.dialect
+	name := QuoteIdent(to.Name, dialect)
+	if dialect == DialectMySQL {
+		if from.Unique && !to.Unique {
+			// MySQL names the index of a UNIQUE column after the column
+			d.alterf("DROP INDEX %s", name)
+		}
+		col := *withoutKey(to)
+		col.Unique = to.Unique && !from.Unique
+		d.alterf("MODIFY COLUMN %s", ColumnSQL(&col, dialect))
+		return
+	}
+
+	if oldType, newType := normalizeType(from.Type, dialect), normalizeType(to.Type, dialect); oldType != newType {
+		typ := to.Type
+		if serial, ok := pgSerialTypes[typ]; ok {
+			// A serial is an integer column with a sequence, which a
+			// changed type keeps
+			typ = serial
+		}
+		d.alterf("ALTER COLUMN %s TYPE %s", name, typ)
+	}
+	if notNull(fromTable, from) != notNull(d.table, to) {
+		if notNull(d.table, to) {
+			d.alterf("ALTER COLUMN %s SET NOT NULL", name)
+		} else {
+			d.alterf("ALTER COLUMN %s DROP NOT NULL", name)
+		}
+	}
+	if !sameDefault(from, to) {
+		switch {
+		case !to.HasDefault:
+			d.alterf("ALTER COLUMN %s DROP DEFAULT", name)
+		case to.DefaultQuoted:
+			d.alterf("ALTER COLUMN %s SET DEFAULT %s", name, quoteString(to.Default))
+		default:
+			d.alterf("ALTER COLUMN %s SET DEFAULT %s", name, to.Default)
+		}
+	}
+	if autoIncrement(from, dialect) != autoIncrement(to, dialect) {
+		if autoIncrement(to, dialect) {
+			d.alterf("ALTER COLUMN %s ADD GENERATED BY DEFAULT AS IDENTITY", name)
+		} else {
+			d.alterf("ALTER COLUMN %s DROP IDENTITY IF EXISTS", name)
+		}
+	}
+	if from.Unique != to.Unique {
+		if to.Unique {
+			d.alterf("ADD UNIQUE (%s)", name)
+		} else {
+			d.alterf("DROP CONSTRAINT %s", QuoteIdent(d.table.Name+"_"+to.Name+"_key", dialect))
+		}
+	}
+	if from.Comment != to.Comment {
+		d.alter = append(d.alter, commentOnColumn(d.table, to))
+	}
+}
+
+// sqliteRebuild recreates a table with its new definition and copies the
+// rows of the columns both versions have
+func sqliteRebuild(from, to *Table) []string {
+	tmp := *to
+	tmp.Name = to.Name + "__new"
+	tmp.Indexes = nil
+	var common []string
+	for _, col := range to.Columns {
+		if from.columnIndex(col.Name) >= 0 {
+			common = append(common, QuoteIdent(col.Name, DialectSQLite))
+		}
+	}
+	name, tmpName := QuoteIdent(to.Name, DialectSQLite), QuoteIdent(tmp.Name, DialectSQLite)
+	stmts := []string{
+		"-- SQLite cannot alter columns or constraints in place: rebuild " + to.Name,
+		strings.TrimSuffix(CreateTableSQL(&tmp, DialectSQLite, nil), "\n"),
+	}
+	if len(common) > 0 {
+		cols := strings.Join(common, ", ")
+		stmts = append(stmts, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;", tmpName, cols, cols, name))
+	}
+	stmts = append(stmts, "DROP TABLE "+name+";", fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", tmpName, name))
+	for _, idx := range to.Indexes {
+		stmts = append(stmts, CreateIndexSQL(to, idx, DialectSQLite)+";")
+	}
+	return stmts
+}
+
+// diffIndexes returns the indexes of from to drop and those of to to create,
+// matched by name; an index whose definition changed is in both
+func diffIndexes(from, to *Table) (drop, add []*Index) {
+	find := func(t *Table, idx *Index) *Index {
+		for _, other := range t.Indexes {
+			if strings.EqualFold(indexName(t, other), indexName(t, idx)) {
+				return other
+			}
+		}
+		return nil
+	}
+	same := func(a, b *Index) bool {
+		return a.Unique == b.Unique && strings.EqualFold(a.Class, b.Class) && a.Method == b.Method &&
+			slices.EqualFunc(a.Columns, b.Columns, strings.EqualFold)
+	}
+	for _, idx := range from.Indexes {
+		if other := find(to, idx); other == nil || !same(idx, other) {
+			drop = append(drop, idx)
+		}
+	}
+	for _, idx := range to.Indexes {
+		if other := find(from, idx); other == nil || !same(idx, other) {
+			add = append(add, idx)
+		}
+	}
+	return drop, add
+}
+
+// diffForeignKeys returns the foreign keys of from to drop and those of to
+// to add. Keys are matched by their columns and target rather than by
+// name, which DDL often leaves out.
+func diffForeignKeys(fromSchema, toSchema *Schema, from, to *Table) (drop, add []*ForeignKey) {
+	key := func(schema *Schema, fk *ForeignKey) string {
+		refs := fk.Reference.Columns
+		if ref := schema.Lookup(fk.Table); len(refs) == 0 && ref != nil {
+			refs = primaryKeyColumns(ref)
+		}
+		return strings.ToLower(fmt.Sprintf("%s>%s%s %s %s", strings.Join(fk.Columns, ","), fk.Table,
+			strings.Join(refs, ","), fk.OnDelete, fk.OnUpdate))
+	}
+	oldKeys := make(map[string]bool)
+	for _, fk := range tableForeignKeys(from) {
+		oldKeys[key(fromSchema, fk)] = true
+	}
+	newKeys := make(map[string]bool)
+	for _, fk := range tableForeignKeys(to) {
+		k := key(toSchema, fk)
+		newKeys[k] = true
+		if !oldKeys[k] {
+			add = append(add, fk)
+		}
+	}
+	for _, fk := range tableForeignKeys(from) {
+		if !newKeys[key(fromSchema, fk)] {
+			drop = append(drop, fk)
+		}
+	}
+	return drop, add
+}
+
+// dropForeignKeySQL drops a foreign key by name. Postgres names unnamed keys
+// <table>_<column>_fkey, MySQL <table>_ibfk_<n> in declaration order.
+func dropForeignKeySQL(table *Table, fk *ForeignKey, dialect string) string {
+	name := fk.Name
+	if name == "" && dialect == DialectPostgres {
+		name = table.Name + "_" + strings.Join(fk.Columns, "_") + "_fkey"
+	}
+	if name == "" && dialect == DialectMySQL {
+		// MySQL ignores inline REFERENCES clauses, which tableForeignKeys
+		// lists after the table-level keys
+		n := 0
+		for _, other := range table.ForeignKeys {
+			if other.Name == "" {
+				n++
+			}
+			if slices.Equal(other.Columns, fk.Columns) && strings.EqualFold(other.Table, fk.Table) {
+				name = fmt.Sprintf("%s_ibfk_%d", table.Name, n)
+				break
+			}
+		}
+	}
+	if name == "" {
+		return fmt.Sprintf("-- drop the unnamed foreign key (%s) of %s by its generated name",
+			strings.Join(fk.Columns, ", "), table.Name)
+	}
+	action := "DROP CONSTRAINT"
+	if dialect == DialectMySQL {
+		action = "DROP FOREIGN KEY"
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s %s;", QuoteIdent(table.Name, dialect), action, QuoteIdent(name, dialect))
+}
+
+// dropIndexSQL drops an index
+func dropIndexSQL(table *Table, idx *Index, dialect string) string {
+	name := QuoteIdent(indexName(table, idx), dialect)
+	if dialect == DialectMySQL {
+		return fmt.Sprintf("ALTER TABLE %s DROP INDEX %s;", QuoteIdent(table.Name, dialect), name)
+	}
+	return "DROP INDEX " + name + ";"
+}
+
+// commentOnColumn sets or clears a Postgres column comment
+func commentOnColumn(table *Table, col *Column) string {
+	return fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;", QuoteIdent(table.Name, DialectPostgres),
+		QuoteIdent(col.Name, DialectPostgres), commentLiteral(col.Comment))
+}
+
+func commentLiteral(comment string) string {
+	if comment == "" {
+		return "NULL"
+	}
+	return quoteString(comment)
+}
+
+// withoutKey returns col without the PRIMARY KEY flag, which ALTER TABLE
+// sets by ADD PRIMARY KEY
+func withoutKey(col *Column) *Column {
+	c := *col
+	c.PrimaryKey = false
+	return &c
+}
+
+// sameColumn reports whether two definitions of a column are equivalent
+func sameColumn(at, bt *Table, a, b *Column, dialect string) bool {
+	return normalizeType(a.Type, dialect) == normalizeType(b.Type, dialect) &&
+		notNull(at, a) == notNull(bt, b) && sameDefault(a, b) &&
+		autoIncrement(a, dialect) == autoIncrement(b, dialect) &&
+		a.Unique == b.Unique && a.Comment == b.Comment &&
+		(dialect != DialectMySQL || slices.Equal(a.Values, b.Values) && strings.EqualFold(a.OnUpdate, b.OnUpdate))
+}
+
+// notNull reports whether a column is NOT NULL, which primary key columns
+// are implicitly
+func notNull(table *Table, col *Column) bool {
+	return col.NotNull || InArray(primaryKeyColumns(table), strings.ToLower(col.Name))
+}
+
+func autoIncrement(col *Column, dialect string) bool {
+	return col.AutoIncrement || (dialect == DialectPostgres && strings.HasSuffix(col.Type, "serial"))
+}
+
+// sameDefault compares defaults, ignoring whether a value was quoted:
+// DEFAULT 0 and DEFAULT '0' are the same default
+func sameDefault(a, b *Column) bool {
+	if a.HasDefault != b.HasDefault {
+		return false
+	}
+	if a.DefaultQuoted && b.DefaultQuoted {
+		return a.Default == b.Default
+	}
+	return strings.EqualFold(a.Default, b.Default)
+}
+
+// pgSerialTypes maps the Postgres serial types to their integer types
+var pgSerialTypes = map[string]string{"smallserial": "smallint", "serial": "integer", "bigserial": "bigint"}
+
+// displayWidth matches the display width of MySQL integer types, which
+// MySQL 8 no longer reports
+var displayWidth = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|integer|bigint)\(\d+\)`)
+
+// typeAliases maps spellings of a type to the one used for comparison
+var typeAliases = map[string]string{
+	"integer":                     "int",
+	"int4":                        "int",
+	"int8":                        "bigint",
+	"int2":                        "smallint",
+	"serial":                      "int",
+	"bigserial":                   "bigint",
+	"smallserial":                 "smallint",
+	"bool":                        "boolean",
+	"float8":                      "double precision",
+	"float4":                      "real",
+	"character varying":           "varchar",
+	"timestamp without time zone": "timestamp",
+	"timestamp with time zone":    "timestamptz",
+	"decimal":                     "numeric",
+}
+
+// normalizeType reduces a declared type to a canonical spelling, so that
+// int(11) and int, or serial and integer, compare equal
+func normalizeType(typ, dialect string) string {
+	typ = strings.Join(strings.Fields(strings.ToLower(typ)), " ")
+	typ = strings.ReplaceAll(typ, " (", "(")
+	if dialect == DialectMySQL && typ != "tinyint(1)" {
+		typ = displayWidth.ReplaceAllString(typ, "$1")
+	}
+	base, rest := typ, ""
+	if i := strings.IndexAny(typ, "( "); i >= 0 {
+		base, rest = typ[:i], typ[i:]
+	}
+	for _, long := range []string{"character varying", "timestamp without time zone", "timestamp with time zone"} {
+		if strings.HasPrefix(typ, long) {
+			base, rest = long, typ[len(long):]
+		}
+	}
+	if alias, ok := typeAliases[base]; ok {
+		base = alias
+	}
+	if dialect == DialectMySQL && base == "boolean" {
+		return "tinyint(1)"
+	}
+	return base + rest
+}
@@ -0,0 +1,81 @@
+package service
+
+import "testing"
+
+func TestMigrationSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  string
+		from, to string
+		want     string
+	}{
+		{
+			name:    "same schema",
+			dialect: DialectMySQL,
+			from:    "CREATE TABLE t (id INT(11) NOT NULL, s enum('a','b'), u datetime ON UPDATE CURRENT_TIMESTAMP, PRIMARY KEY (id));",
+			to:      "CREATE TABLE `t` (`id` int NOT NULL PRIMARY KEY, `s` ENUM('a','b'), `u` datetime ON UPDATE current_timestamp);",
+			want:    "",
+		},
+		{
+			name:    "add and drop tables",
+			dialect: DialectMySQL,
+			from:    "CREATE TABLE a (id int NOT NULL, PRIMARY KEY (id));",
+			to:      "CREATE TABLE b (id int NOT NULL, PRIMARY KEY (id));",
+			want:    "DROP TABLE `a`;\nCREATE TABLE `b` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n);\n",
+		},
+		{
+			name:    "add, modify and drop columns",
+			dialect: DialectMySQL,
+			from:    "CREATE TABLE t (id int NOT NULL, a int, b int, PRIMARY KEY (id));",
+			to:      "CREATE TABLE t (id int NOT NULL, a bigint NOT NULL, c text, PRIMARY KEY (id));",
+			want:    "ALTER TABLE `t` DROP COLUMN `b`;\nALTER TABLE `t` ADD COLUMN `c` text AFTER `a`;\nALTER TABLE `t` MODIFY COLUMN `a` bigint NOT NULL;\n",
+		},
+		{
+			name:    "changed enum members",
+			dialect: DialectMySQL,
+			from:    "CREATE TABLE t (s enum('a','b'));",
+			to:      "CREATE TABLE t (s enum('a','b','c'));",
+			want:    "ALTER TABLE `t` MODIFY COLUMN `s` enum('a','b','c');\n",
+		},
+		{
+			name:    "postgres column and index",
+			dialect: DialectPostgres,
+			from:    "CREATE TABLE t (id int PRIMARY KEY, a int);",
+			to:      "CREATE TABLE t (id int PRIMARY KEY, a bigint NOT NULL DEFAULT 0); CREATE INDEX idx_a ON t (a);",
+			want:    "ALTER TABLE \"t\" ALTER COLUMN \"a\" TYPE bigint;\nALTER TABLE \"t\" ALTER COLUMN \"a\" SET NOT NULL;\nALTER TABLE \"t\" ALTER COLUMN \"a\" SET DEFAULT 0;\nCREATE INDEX \"idx_a\" ON \"t\" (\"a\");\n",
+		},
+		{
+			name:    "foreign key names are kept",
+			dialect: DialectMySQL,
+			from: `CREATE TABLE u (id int NOT NULL, PRIMARY KEY (id));
+CREATE TABLE t (u_id int, CONSTRAINT fk_owner FOREIGN KEY (u_id) REFERENCES u (id));`,
+			to: `CREATE TABLE u (id int NOT NULL, PRIMARY KEY (id));
+CREATE TABLE t (u_id int CONSTRAINT fk_owner REFERENCES u (id));`,
+			want: "",
+		},
+		{
+			name:    "changed foreign key action",
+			dialect: DialectPostgres,
+			from: `CREATE TABLE u (id int PRIMARY KEY);
+CREATE TABLE t (u_id int CONSTRAINT fk_owner REFERENCES u (id));`,
+			to: `CREATE TABLE u (id int PRIMARY KEY);
+CREATE TABLE t (u_id int CONSTRAINT fk_owner REFERENCES u (id) ON DELETE CASCADE);`,
+			want: "ALTER TABLE \"t\" DROP CONSTRAINT \"fk_owner\";\nALTER TABLE \"t\" ADD CONSTRAINT \"fk_owner\" FOREIGN KEY (\"u_id\") REFERENCES \"u\" (\"id\") ON DELETE CASCADE;\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, err := ParseDDL("from.sql", tt.from, tt.dialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			to, err := ParseDDL("to.sql", tt.to, tt.dialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := MigrationSQL(from, to, tt.dialect); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,329 +1,727 @@
 package service
 
 import (
-	"bufio"
 	"fmt"
-	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 type fieldInfo struct {
-	name     string
-	typeName string
-	gormTags string
-	jsonTag  string
+	name      string // Empty for a comment-only line
+	typeName  string
+	valueType string // typeName without the nullable wrapper, e.g. string for *string
+	gormTags  string
+	jsonTag   string
+	doc       string // Comment written above the field
+
+	importPath string // Package of a type_mapping type or associated model
 }
 
-func ExtractCreateTables(filePath string) ([]string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var createTables []string
-	scanner := bufio.NewScanner(file)
-	var currentStmt strings.Builder
-	capturing := false
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+// typePackages maps the qualifiers of generated field types to import
+// paths; lib/ paths are relative to the project root
+var typePackages = map[string]string{
+	"decimal": "github.com/shopspring/decimal",
+	"json":    "encoding/json",
+	"mytime":  "lib/mytime",
+	"pq":      "github.com/lib/pq",
+	"sql":     "database/sql",
+}
 
-		if strings.HasPrefix(line, "--") || strings.HasPrefix(line, "/*") {
-			continue
+// qualifierPattern matches the package qualifiers of a Go type
+var qualifierPattern = regexp.MustCompile(`\b([A-Za-z_]\w*)\.`)
+
+// fieldImports returns the import paths of the packages the fields' types
+// come from, split into absolute paths and paths relative to the project.
+// Qualifiers of type_mapping types without an import are left to goimports.
+func fieldImports(fields []fieldInfo) (imports, projectImports []string) {
+	add := func(path string) {
+		switch {
+		case strings.HasPrefix(path, "lib/") || strings.HasPrefix(path, "model/"):
+			if !InArray(projectImports, path) {
+				projectImports = append(projectImports, path)
+			}
+		case !InArray(imports, path):
+			imports = append(imports, path)
 		}
-		if strings.HasPrefix(line, "CREATE TABLE") {
-			capturing = true
-			currentStmt.WriteString(line + "\n")
+	}
+	for _, f := range fields {
+		if f.importPath != "" {
+			add(f.importPath)
 			continue
 		}
-
-		if strings.HasPrefix(line, ")") && strings.HasSuffix(line, ";") {
-			capturing = false
-			currentStmt.WriteString(line + "\n")
-			createTables = append(createTables, currentStmt.String())
-			currentStmt = strings.Builder{}
-		}
-
-		if capturing {
-			currentStmt.WriteString(line + "\n")
+		for _, m := range qualifierPattern.FindAllStringSubmatch(f.typeName, -1) {
+			if path, ok := typePackages[m[1]]; ok {
+				add(path)
+			}
 		}
 	}
+	return imports, projectImports
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
+// ModelStruct is the Go model generated for a table
+type ModelStruct struct {
+	Table   *Table
+	Name    string   // Struct name
+	Source  string   // Struct declaration
+	Imports []string // Import paths of the packages of field types
+	// Project packages of field types, relative to the project root, e.g.
+	// lib/mytime or model/user
+	ProjectImports []string
+	PKField        string // Primary key field, used by Record.Exists and Read; empty without a single integer or string key
+	PKType         string // Parameter type of Record.Read
+	PKExists       string // Condition on PKField meaning the record is stored, e.g. "> 0"
+	Enums          []ModelEnum
+	Columns        []ModelColumn
+	Finders        []ModelFinder
+}
 
-	// 处理最后可能未闭合的语句
-	if capturing && currentStmt.Len() > 0 {
-		createTables = append(createTables, currentStmt.String())
-	}
+// ModelColumn is a column of a model and the field it maps to
+type ModelColumn struct {
+	Name       string // Column name
+	Field      string // Struct field name
+	Type       string // Go type of the column's values, without the nullable wrapper
+	Comparable bool   // Values can be compared with = and IN, unlike JSON and arrays
+}
 
-	return createTables, nil
+// ModelFinder is a FindBy method on the columns of a key: a Record method
+// for a primary or unique key, a List method for other indexes
+type ModelFinder struct {
+	Name    string // e.g. FindByEmail or FindByOrgIdName
+	Unique  bool
+	Columns []ModelColumn
 }
 
-// GenerateStruct generates Go struct definition from SQL create table statement
-func GenerateModelStruct(sql string) (string, string, error) {
-	tableName, fields, err := parseSQL(sql)
-	if err != nil {
-		return "", "", err
-	}
+// ModelEnum is the named string type generated for an ENUM or SET column
+type ModelEnum struct {
+	Name   string // Type name, e.g. Status
+	Column string
+	Set    bool     // SET column, whose values are comma-separated members
+	Values []string // Declared values
+	Consts []string // Constant name of each value, e.g. StatusPending
+}
+
+// Ways of mapping nullable columns
+const (
+	NullableZero    = "zero"    // Value types, NULL reads as the zero value
+	NullablePointer = "pointer" // Pointers, e.g. *int64
+	NullableSQLNull = "sqlnull" // sql.Null types, e.g. sql.NullInt64
+)
+
+// NullableModes lists the supported nullable column mappings
+var NullableModes = []string{NullableZero, NullablePointer, NullableSQLNull}
 
-	return buildStruct(tableName, fields), toCamelCase(tableName), nil
+// ModelOptions controls how models are generated from a schema
+type ModelOptions struct {
+	Dialect     string // SQL dialect of the DDL, see Dialects
+	CommentTags bool   // Also write column comments as gorm comment settings
+	Nullable    string // Mapping of nullable columns, see NullableModes
+	TypeMapping map[string]TypeOverride
 }
 
-func parseSQL(sql string) (string, []fieldInfo, error) {
-	tableName, err := extractTableName(sql)
-	if err != nil {
-		return "", nil, err
-	}
+// tagOrder is the order in which column settings appear in a gorm tag
+var tagOrder = []string{"type", "size", "precision", "scale", "primaryKey", "autoIncrement", "unsigned", "notNull", "unique", "default", "comment", "->"}
 
-	fieldDefinitions, err := extractFieldDefinitions(sql)
-	if err != nil {
-		return "", nil, err
-	}
+// modelGenerator generates the models of a schema, tracking the imports
+// between model packages so that association fields never form a cycle
+type modelGenerator struct {
+	schema *Schema
+	opts   ModelOptions
+	models map[string][]string // Model package -> model packages it imports
+}
 
-	var fields []fieldInfo
-	for _, def := range fieldDefinitions {
-		fi, err := parseField(def)
-		if err != nil {
-			return "", nil, err
-		}
-		if fi.name == "" {
-			continue
-		}
-		fields = append(fields, fi)
+// GenerateModels generates the Go struct of every table of a parsed schema.
+// Keys and indexes become gorm tags and foreign keys to tables of the
+// schema become belongs-to association fields, so that AutoMigrate
+// recreates the schema. Table and column comments become doc comments.
+func GenerateModels(schema *Schema, opts ModelOptions) []ModelStruct {
+	g := &modelGenerator{schema: schema, opts: opts, models: make(map[string][]string)}
+	models := make([]ModelStruct, 0, len(schema.Tables))
+	for _, table := range schema.Tables {
+		models = append(models, g.generate(table))
 	}
-
-	return tableName, fields, nil
+	return models
 }
 
-func extractTableName(sql string) (string, error) {
-	re := regexp.MustCompile(`(?i)CREATE\s+TABLE\s+[\x60]?(\w+)[\x60]?`)
-	matches := re.FindStringSubmatch(sql)
-	if len(matches) < 2 {
-		return "", fmt.Errorf("table name not found")
+func (g *modelGenerator) generate(table *Table) ModelStruct {
+	pk := primaryKeyColumns(table)
+	indexes := indexTags(table)
+	m := ModelStruct{
+		Table: table,
+		Name:  toCamelCase(table.Name),
+	}
+	fields := make([]fieldInfo, 0, len(table.Columns))
+	for _, col := range table.Columns {
+		key := strings.ToLower(col.Name)
+		enumType := ""
+		if _, overridden := lookupTypeOverride(g.opts.TypeMapping, col.Type); len(col.Values) > 0 && !overridden {
+			enum := newModelEnum(m.Name, col, m.Enums)
+			m.Enums = append(m.Enums, enum)
+			enumType = enum.Name
+		}
+		fields = append(fields, columnField(col, InArray(pk, key), indexes[key], enumType, g.opts))
+	}
+	assocs := g.associations(table, fields)
+	doc := ""
+	if table.Comment != "" {
+		doc = m.Name + " " + table.Comment
+	}
+	fields = append(fields, assocs...)
+	m.Source = buildStruct(table.Name, doc, fields)
+	m.Imports, m.ProjectImports = fieldImports(fields)
+
+	// Record.Exists and Read need a single integer or string key
+	if len(pk) == 1 {
+		for i, col := range table.Columns {
+			if strings.ToLower(col.Name) != pk[0] {
+				continue
+			}
+			switch t := fields[i].typeName; {
+			case strings.HasPrefix(t, "int") || strings.HasPrefix(t, "uint"):
+				m.PKField, m.PKType, m.PKExists = fields[i].name, t, "> 0"
+			case t == "string":
+				m.PKField, m.PKType, m.PKExists = fields[i].name, "string", `!= ""`
+			}
+		}
 	}
-	return toCamelCase(matches[1]), nil
+	m.Columns, m.Finders = queryColumns(table, fields[:len(table.Columns)], m.PKField != "")
+	return m
 }
 
-// 改进：基于括号层级与引号状态提取字段定义，避免被类型/注释/索引内的逗号误分割
-func extractFieldDefinitions(sql string) ([]string, error) {
-	// 找到第一个左括号（字段定义起点）
-	start := strings.Index(sql, "(")
-	if start < 0 {
-		return nil, fmt.Errorf("field definitions not found")
+// queryColumns returns the columns of a model and the finders of its
+// primary, unique and normal keys. Columns of types without equality, such
+// as JSON and arrays, are not comparable and have no finder; nor has the
+// primary key when Record.Read looks it up, as keyRead reports.
+func queryColumns(table *Table, fields []fieldInfo, keyRead bool) ([]ModelColumn, []ModelFinder) {
+	columns := make([]ModelColumn, len(table.Columns))
+	byName := make(map[string]int, len(table.Columns))
+	for i, col := range table.Columns {
+		t := fields[i].valueType
+		columns[i] = ModelColumn{
+			Name:       col.Name,
+			Field:      fields[i].name,
+			Type:       t,
+			Comparable: !strings.HasPrefix(t, "[]") && !strings.HasPrefix(t, "pq.") && t != "json.RawMessage",
+		}
+		byName[strings.ToLower(col.Name)] = i
 	}
 
-	// 从 start 向后找到匹配的右括号（考虑嵌套）
-	level := 0
-	end := -1
-	for i := start; i < len(sql); i++ {
-		ch := sql[i]
-		if ch == '(' {
-			level++
-		} else if ch == ')' {
-			level--
-			if level == 0 {
-				end = i
-				break
+	var finders []ModelFinder
+	addKey := func(names []string, unique bool) {
+		key := ModelFinder{Name: "FindBy", Unique: unique}
+		for _, name := range names {
+			i, ok := byName[strings.ToLower(name)]
+			if !ok || !columns[i].Comparable {
+				return
+			}
+			key.Name += columns[i].Field
+			key.Columns = append(key.Columns, columns[i])
+		}
+		for _, f := range finders {
+			if f.Name == key.Name {
+				return
 			}
 		}
+		finders = append(finders, key)
 	}
-	if end == -1 || end <= start {
-		return nil, fmt.Errorf("field definitions not found (unmatched parentheses)")
+	if pk := primaryKeyColumns(table); len(pk) > 0 && !keyRead {
+		addKey(pk, true)
 	}
+	for _, col := range table.Columns {
+		if col.Unique && !col.PrimaryKey {
+			addKey([]string{col.Name}, true)
+		}
+	}
+	for _, idx := range table.Indexes {
+		if idx.Class == "" {
+			addKey(idx.Columns, idx.Unique)
+		}
+	}
+	return columns, finders
+}
 
-	inner := sql[start+1 : end]
-
-	// 按最外层逗号分割（忽略括号内和引号内的逗号）
-	defs := splitFieldDefinitions(inner)
-
-	// 清理并返回
-	out := make([]string, 0, len(defs))
-	for _, d := range defs {
-		s := strings.TrimSpace(d)
-		if s != "" {
-			out = append(out, s)
+// primaryKeyColumns returns the lower-cased primary key columns of table,
+// declared either by a table-level PRIMARY KEY or on the columns
+func primaryKeyColumns(table *Table) []string {
+	var pk []string
+	for _, c := range table.PrimaryKey {
+		pk = append(pk, strings.ToLower(c))
+	}
+	for _, col := range table.Columns {
+		if col.PrimaryKey && !InArray(pk, strings.ToLower(col.Name)) {
+			pk = append(pk, strings.ToLower(col.Name))
 		}
 	}
-	return out, nil
+	return pk
 }
 
-// splitFieldDefinitions: 在没有正则限制下按最外层逗号切分定义
-// 新增：考虑单/双引号以及反斜杠转义，避免在引号内分割
-func splitFieldDefinitions(body string) []string {
-	var defs []string
-	var cur strings.Builder
-	level := 0
-	inSingle := false
-	inDouble := false
-	escaped := false
+// indexTags maps each lower-cased column to the gorm index and uniqueIndex
+// settings of the indexes it belongs to. Columns of a composite index get
+// their position as priority; unnamed composite indexes are given gorm's
+// default name so that their columns are grouped.
+func indexTags(table *Table) map[string][]string {
+	tags := make(map[string][]string)
+	for _, idx := range table.Indexes {
+		key := "index"
+		if idx.Unique {
+			key = "uniqueIndex"
+		}
+		name := idx.Name
+		if name == "" && len(idx.Columns) > 1 {
+			name = "idx_" + table.Name + "_" + strings.Join(idx.Columns, "_")
+		}
+		for i, c := range idx.Columns {
+			var opts []string
+			if idx.Class != "" {
+				opts = append(opts, "class:"+idx.Class)
+			}
+			if idx.Method != "" {
+				opts = append(opts, "type:"+idx.Method)
+			}
+			if len(idx.Columns) > 1 {
+				opts = append(opts, fmt.Sprintf("priority:%d", i+1))
+			}
+			setting := key
+			if name != "" || len(opts) > 0 {
+				setting += ":" + escapeTagValue(name)
+			}
+			if len(opts) > 0 {
+				setting += "," + strings.Join(opts, ",")
+			}
+			c = strings.ToLower(c)
+			tags[c] = append(tags[c], setting)
+		}
+	}
+	return tags
+}
 
-	for i := 0; i < len(body); i++ {
-		ch := body[i]
+// associations returns a belongs-to field for each foreign key of table.
+// Foreign keys that cannot be mapped are noted by a comment instead. A
+// constraint name other than gorm's fk_<table>_<field> is kept in the
+// constraint tag.
+func (g *modelGenerator) associations(table *Table, fields []fieldInfo) []fieldInfo {
+	fks := tableForeignKeys(table)
 
-		// 处理转义：如果前一个字符是反斜杠，则当前字符是被转义的
-		if ch == '\\' && !escaped {
-			escaped = true
-			cur.WriteByte(ch)
+	taken := make(map[string]bool)
+	for _, f := range fields {
+		taken[f.name] = true
+	}
+	pkg := strings.ToLower(toCamelCase(table.Name))
+	var assocs []fieldInfo
+	for _, fk := range fks {
+		desc := fmt.Sprintf("Foreign key (%s) -> %s(%s)", strings.Join(fk.Columns, ", "), fk.Table,
+			strings.Join(fk.Reference.Columns, ", "))
+		ref := g.schema.Lookup(fk.Table)
+		if ref == nil {
+			assocs = append(assocs, fieldInfo{doc: desc + " is not mapped: " + fk.Table + " is not declared in the DDL"})
+			continue
+		}
+		refCols := fk.Reference.Columns
+		if len(refCols) == 0 {
+			refCols = primaryKeyColumns(ref)
+		}
+		if len(refCols) != len(fk.Columns) {
+			assocs = append(assocs, fieldInfo{doc: desc + " is not mapped: the column counts differ"})
 			continue
 		}
 
-		// 如果在单引号或双引号内，被转义字符不作为引号结束
-		if !escaped {
-			if ch == '\'' && !inDouble {
-				inSingle = !inSingle
-				cur.WriteByte(ch)
+		refStruct := toCamelCase(ref.Name)
+		typeName, importPath := "*"+refStruct, ""
+		if ref != table {
+			refPkg := strings.ToLower(refStruct)
+			if g.reaches(refPkg, pkg) {
+				assocs = append(assocs, fieldInfo{doc: desc + " is not mapped: model " + refPkg + " already depends on " + pkg})
 				continue
 			}
-			if ch == '"' && !inSingle {
-				inDouble = !inDouble
-				cur.WriteByte(ch)
-				continue
+			if !InArray(g.models[pkg], refPkg) {
+				g.models[pkg] = append(g.models[pkg], refPkg)
 			}
+			typeName, importPath = "*"+refPkg+"."+refStruct, "model/"+refPkg
 		}
 
-		// 非引号中且非转义，处理括号层级
-		if !inSingle && !inDouble {
-			if ch == '(' {
-				level++
-			} else if ch == ')' {
-				if level > 0 {
-					level--
-				}
+		// user_id -> User, falling back to the referenced struct name
+		base := ref.Name
+		if len(fk.Columns) == 1 {
+			if trimmed := strings.TrimSuffix(strings.ToLower(fk.Columns[0]), "_id"); trimmed != strings.ToLower(fk.Columns[0]) {
+				base = trimmed
 			}
 		}
+		name := toCamelCase(base)
+		for i := 2; taken[name]; i++ {
+			name = fmt.Sprintf("%s%d", toCamelCase(base), i)
+		}
+		taken[name] = true
 
-		// 分割条件：最外层逗号（level==0）且不在任意引号内
-		if ch == ',' && level == 0 && !inSingle && !inDouble {
-			part := strings.TrimSpace(cur.String())
-			if part != "" {
-				defs = append(defs, part)
-			}
-			cur.Reset()
-			escaped = false
-			continue
+		settings := []string{
+			"foreignKey:" + camelList(fk.Columns),
+			"references:" + camelList(refCols),
+		}
+		var actions []string
+		if fk.OnUpdate != "" {
+			actions = append(actions, "OnUpdate:"+fk.OnUpdate)
+		}
+		if fk.OnDelete != "" {
+			actions = append(actions, "OnDelete:"+fk.OnDelete)
+		}
+		switch {
+		case fk.Name != "" && !strings.EqualFold(fk.Name, "fk_"+table.Name+"_"+toDBName(name)):
+			// gorm only reads the name when a comma follows it
+			settings = append(settings, "constraint:"+fk.Name+","+strings.Join(actions, ","))
+		case len(actions) > 0:
+			settings = append(settings, "constraint:"+strings.Join(actions, ","))
 		}
+		assocs = append(assocs, fieldInfo{
+			name:       name,
+			typeName:   typeName,
+			gormTags:   strings.Join(settings, ";"),
+			jsonTag:    toSnakeCase(base) + ",omitempty",
+			importPath: importPath,
+		})
+	}
+	return assocs
+}
 
-		// 正常写入当前字符
-		cur.WriteByte(ch)
-		// 重置 escaped 标志（只有紧接着的字符被转义）
-		escaped = false
+// reaches reports whether model package from imports to, directly or not
+func (g *modelGenerator) reaches(from, to string) bool {
+	if from == to {
+		return true
 	}
+	for _, next := range g.models[from] {
+		if g.reaches(next, to) {
+			return true
+		}
+	}
+	return false
+}
 
-	// 追加剩余
-	if s := strings.TrimSpace(cur.String()); s != "" {
-		defs = append(defs, s)
+func camelList(columns []string) string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = toCamelCase(c)
 	}
-	return defs
+	return strings.Join(names, ",")
 }
 
-func parseField(def string) (fieldInfo, error) {
-	// Improved regex to fully capture type description
-	re := regexp.MustCompile("[\x60]?(\\w+)[\x60]?\\s+(.+)")
-	matches := re.FindStringSubmatch(def)
-	if len(matches) < 3 {
-		return fieldInfo{}, fmt.Errorf("invalid field definition: %s", def)
+// reservedEnumNames are identifiers of the model package an enum type
+// must not take
+var reservedEnumNames = []string{"Record", "List", "NewRecord", "NewList", "Query"}
+
+// newModelEnum names the type of an ENUM or SET column after the column,
+// prefixed with the struct name when that would clash with an identifier
+// of the package, and names a constant after each value
+func newModelEnum(structName string, col *Column, existing []ModelEnum) ModelEnum {
+	name := toCamelCase(col.Name)
+	clash := name == structName || InArray(reservedEnumNames, name)
+	for _, e := range existing {
+		clash = clash || e.Name == name
+	}
+	if clash {
+		name = structName + name
 	}
 
-	fieldName := matches[1]
-	// 仅对以小写字母开头的 Column 名进行生成��保持旧逻辑）
-	if len(fieldName) == 0 || []byte(fieldName)[0] < 'a' || []byte(fieldName)[0] > 'z' {
-		return fieldInfo{}, nil
+	enum := ModelEnum{
+		Name:   name,
+		Column: col.Name,
+		Set:    strings.HasPrefix(col.Type, "set"),
+		Values: col.Values,
+	}
+	for i, v := range col.Values {
+		constName := name + identifierPart(v)
+		if identifierPart(v) == "" || InArray(enum.Consts, constName) {
+			constName = fmt.Sprintf("%s%d", name, i+1)
+		}
+		enum.Consts = append(enum.Consts, constName)
 	}
-	typeInfo := strings.ToLower(strings.TrimSpace(matches[2]))
+	return enum
+}
 
-	// 保留原有类型映射
-	goType, tags := mapTypeAndTags(typeInfo)
+// identifierPart turns a value such as "on-hold" into OnHold, keeping
+// letters and digits only
+func identifierPart(v string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range v {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 && v == "" {
+		return "Empty"
+	}
+	return b.String()
+}
+
+// columnField maps a column to a struct field with gorm and json tags.
+// primaryKey marks columns of a table-level PRIMARY KEY, indexes holds the
+// column's index settings (see indexTags) and enumType the named type of
+// an ENUM or SET column.
+func columnField(col *Column, primaryKey bool, indexes []string, enumType string, opts ModelOptions) fieldInfo {
+	goType, tags := mapTypeAndTags(col.Type, opts.Dialect)
+	override, overridden := lookupTypeOverride(opts.TypeMapping, col.Type)
+	switch {
+	case overridden:
+		goType = override.Type
+	case enumType != "":
+		goType = enumType
+		if _, ok := tags["type"]; !ok {
+			// Postgres enum types are referenced by name
+			tags["type"] = col.Type
+		}
+	}
+	valueType := goType
+	if !col.NotNull && !col.PrimaryKey && !primaryKey && !col.AutoIncrement {
+		goType = nullableType(goType, opts.Nullable)
+	}
 
-	// 保守增强：识别常见约束并加入 tags（不改变 goType 的映射）
-	if strings.Contains(typeInfo, "unsigned") {
+	// 识别常见约束并加入 tags（不改变 goType 的映射）
+	if strings.Contains(col.Type, "unsigned") {
 		tags["unsigned"] = "true"
 	}
-	if strings.Contains(typeInfo, "auto_increment") || strings.Contains(typeInfo, "autoincrement") {
+	// SQLite: INTEGER PRIMARY KEY aliases the rowid, which is assigned on insert
+	if col.AutoIncrement || (opts.Dialect == DialectSQLite && col.PrimaryKey && col.Type == "integer") {
 		tags["autoIncrement"] = "true"
 	}
-	if strings.Contains(typeInfo, "primary key") || strings.Contains(typeInfo, "primary_key") {
+	if col.PrimaryKey || primaryKey {
 		tags["primaryKey"] = "true"
 	}
-	if strings.Contains(typeInfo, "not null") {
+	if col.NotNull {
 		tags["notNull"] = "true"
 	}
-	// default 值提取（尽量简单提取）
-	if idx := strings.Index(strings.ToUpper(typeInfo), "DEFAULT "); idx >= 0 {
-		after := strings.TrimSpace(typeInfo[idx+8:])
-		if after != "" {
-			if strings.HasPrefix(after, "'") || strings.HasPrefix(after, "\"") {
-				q := after[0]
-				if j := strings.IndexByte(after[1:], q); j >= 0 {
-					tags["default"] = after[1 : 1+j]
-				} else {
-					tags["default"] = strings.Trim(after, "'\"")
-				}
-			} else {
-				parts := strings.Fields(after)
-				if len(parts) > 0 {
-					tags["default"] = strings.TrimRight(parts[0], ",")
-				}
-			}
+	if col.Unique {
+		tags["unique"] = "true"
+	}
+	if col.HasDefault {
+		tags["default"] = col.Default
+	}
+	if col.Generated != "" {
+		// the database computes generated columns, gorm must not write them
+		tags["->"] = "true"
+	}
+	if col.Comment != "" && opts.CommentTags {
+		tags["comment"] = col.Comment
+	}
+	if col.OnUpdate != "" && opts.Dialect == DialectMySQL {
+		// gorm has no ON UPDATE setting, but writes the type as is
+		if _, ok := tags["type"]; !ok {
+			tags["type"] = col.Type
 		}
+		tags["type"] += " on update " + col.OnUpdate
 	}
 
 	return fieldInfo{
-		name:     toCamelCase(fieldName),
-		typeName: goType,
-		gormTags: buildGormTags(fieldName, tags),
-		jsonTag:  toSnakeCase(fieldName),
-	}, nil
+		name:       toCamelCase(col.Name),
+		typeName:   goType,
+		valueType:  valueType,
+		gormTags:   buildGormTags(col.Name, tags, indexes),
+		jsonTag:    toSnakeCase(col.Name),
+		doc:        col.Comment,
+		importPath: override.Import,
+	}
 }
 
-func mapTypeAndTags(sqlType string) (string, map[string]string) {
+// nullableType returns the Go type of a nullable column of type goType.
+// Slices and pq arrays are returned as is since nil already stands for NULL.
+func nullableType(goType, mode string) string {
+	if strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "pq.") || goType == "json.RawMessage" {
+		return goType
+	}
+	switch mode {
+	case NullablePointer:
+		return "*" + goType
+	case NullableSQLNull:
+		switch goType {
+		case "string":
+			return "sql.NullString"
+		case "bool":
+			return "sql.NullBool"
+		case "int64":
+			return "sql.NullInt64"
+		case "int32":
+			return "sql.NullInt32"
+		case "int16":
+			return "sql.NullInt16"
+		case "uint8":
+			return "sql.NullByte"
+		case "float64":
+			return "sql.NullFloat64"
+		case "mytime.DateTime":
+			return "mytime.NullDateTime"
+		case "decimal.Decimal":
+			return "decimal.NullDecimal"
+		default:
+			return "sql.Null[" + goType + "]"
+		}
+	}
+	return goType
+}
+
+func mapTypeAndTags(sqlType, dialect string) (string, map[string]string) {
+	switch dialect {
+	case DialectPostgres:
+		return mapPostgresType(sqlType)
+	case DialectSQLite:
+		return mapSQLiteType(sqlType)
+	}
+	return mapMySQLType(sqlType)
+}
+
+// mysqlTypePattern captures the type name and the arguments of a
+// lower-cased MySQL column type, e.g. decimal and 10,2 in decimal(10,2)
+var mysqlTypePattern = regexp.MustCompile(`^(\w+)(?:\s+precision)?\s*(?:\((.*?)\))?`)
+
+// argsPattern matches the arguments of a column type, e.g. (10,2)
+var argsPattern = regexp.MustCompile(`\s*\(.*?\)`)
+
+// mapMySQLType maps a lower-cased MySQL column type to a Go type and the
+// gorm tags of types a Go type alone does not determine
+func mapMySQLType(sqlType string) (string, map[string]string) {
 	tags := make(map[string]string)
-	baseType := regexp.MustCompile(`^(\w+)(?:\(.*?\))?`).FindString(sqlType)
-	baseType = strings.ToLower(baseType)
+	m := mysqlTypePattern.FindStringSubmatch(sqlType)
+	if m == nil {
+		return "string", tags
+	}
+	baseType, args := m[1], m[2]
 	unsigned := strings.Contains(sqlType, "unsigned")
-
-	var goType string
-	switch {
-	case strings.HasPrefix(baseType, "tinyint"):
+	integer := func(signed, unsignedType string) string {
 		if unsigned {
-			goType = "uint8"
-		} else {
-			goType = "int8"
+			return unsignedType
 		}
-	case strings.HasPrefix(baseType, "int") || strings.HasPrefix(baseType, "bigint"):
-		if unsigned {
-			goType = "uint64"
-		} else {
-			goType = "int64"
-		}
-	case strings.HasPrefix(baseType, "decimal"):
-		goType = "decimal.Decimal"
-	case strings.Contains(baseType, "datetime") || strings.Contains(baseType, "timestamp"):
-		goType = "mytime.DateTime"
-	case strings.HasPrefix(baseType, "varchar"), strings.HasPrefix(baseType, "text"):
-		goType = "string"
-	case strings.HasPrefix(baseType, "boolean"):
-		goType = "bool"
+		return signed
+	}
+
+	switch baseType {
+	case "tinyint":
+		if args == "1" {
+			return "bool", tags
+		}
+		return integer("int8", "uint8"), tags
+	case "bool", "boolean":
+		return "bool", tags
+	case "smallint":
+		return integer("int16", "uint16"), tags
+	case "mediumint", "int", "integer":
+		return integer("int32", "uint32"), tags
+	case "bigint":
+		return integer("int64", "uint64"), tags
+	case "serial":
+		return "uint64", tags
+	case "decimal", "numeric", "dec", "fixed":
+		decimalTags(tags, args)
+		return "decimal.Decimal", tags
+	case "float":
+		// FLOAT(p) is stored as DOUBLE when p > 24
+		if p, err := strconv.Atoi(args); err == nil && p > 24 {
+			return "float64", tags
+		}
+		return "float32", tags
+	case "double", "real":
+		return "float64", tags
+	case "datetime", "timestamp":
+		return "mytime.DateTime", tags
+	case "date":
+		tags["type"] = "date"
+		return "mytime.DateTime", tags
+	case "time":
+		// TIME ranges over -838:59:59..838:59:59, wider than a time of day
+		tags["type"] = m[0]
+		return "string", tags
+	case "year":
+		tags["type"] = "year"
+		return "int16", tags
+	case "json":
+		tags["type"] = "json"
+		return "json.RawMessage", tags
+	case "enum", "set":
+		tags["type"] = sqlType
+		return "string", tags
+	case "bit":
+		tags["type"] = m[0]
+		return "[]byte", tags
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob",
+		"geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection":
+		return "[]byte", tags
+	case "varchar":
+		// the length survives AutoMigrate and god gen ddl
+		if args != "" {
+			tags["size"] = args
+		}
+		return "string", tags
+	case "longtext":
+		return "string", tags
 	default:
-		goType = "string"
+		// char, tinytext, text, mediumtext: kept so that god gen ddl does not
+		// turn them into varchar or longtext
+		tags["type"] = m[0]
+		return "string", tags
+	}
+}
+
+// decimalTags sets the precision and scale tags of decimal(p,s) arguments
+func decimalTags(tags map[string]string, args string) {
+	precision, scale, _ := strings.Cut(args, ",")
+	if precision = strings.TrimSpace(precision); precision != "" {
+		tags["precision"] = precision
 	}
+	if scale = strings.TrimSpace(scale); scale != "" {
+		tags["scale"] = scale
+	}
+}
 
-	return goType, tags
+// lookupTypeOverride finds the type_mapping entry of a lower-cased column
+// type, trying the full type, the type without arguments and the type name:
+// "decimal(10,2) unsigned", "decimal unsigned" then "decimal"
+func lookupTypeOverride(mapping map[string]TypeOverride, sqlType string) (TypeOverride, bool) {
+	if len(mapping) == 0 {
+		return TypeOverride{}, false
+	}
+	normalize := func(t string) string {
+		return strings.ToLower(strings.Join(strings.Fields(t), " "))
+	}
+	withoutArgs := normalize(argsPattern.ReplaceAllString(sqlType, ""))
+	candidates := []string{normalize(sqlType), withoutArgs}
+	if fields := strings.Fields(withoutArgs); len(fields) > 0 {
+		candidates = append(candidates, fields[0])
+	}
+	for _, c := range candidates {
+		for k, v := range mapping {
+			if normalize(k) == c {
+				return v, true
+			}
+		}
+	}
+	return TypeOverride{}, false
 }
 
-func buildGormTags(fieldName string, tags map[string]string) string {
+// buildGormTags joins the column name, the settings of tags in tagOrder
+// and the extra settings into a gorm tag
+func buildGormTags(fieldName string, tags map[string]string, extra []string) string {
 	parts := []string{"column:" + fieldName}
-	for k, v := range tags {
-		if v == "true" {
+	for _, k := range tagOrder {
+		v, ok := tags[k]
+		switch {
+		case !ok:
+		case v == "true" && k != "default":
 			parts = append(parts, k)
-		} else {
-			parts = append(parts, fmt.Sprintf("%s:%s", k, v))
+		default:
+			parts = append(parts, fmt.Sprintf("%s:%s", k, escapeTagValue(v)))
 		}
 	}
-	return strings.Join(parts, ";")
+	return strings.Join(append(parts, extra...), ";")
+}
+
+// escapeTagValue escapes a gorm tag setting value for use inside a struct
+// tag: ";" would end the setting, \ and " would end the tag string, and
+// newlines and backquotes cannot appear in the raw string literal
+func escapeTagValue(v string) string {
+	v = strings.ReplaceAll(v, ";", `\;`)
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "`", `\x60`).Replace(v)
 }
 
 func isSpecialType(t string) bool {
@@ -342,11 +740,16 @@ func toSnakeCase(s string) string {
 	return strings.ToLower(s)
 }
 
-func buildStruct(tableName string, fields []fieldInfo) string {
+func buildStruct(tableName, doc string, fields []fieldInfo) string {
 	var sb strings.Builder
+	writeDoc(&sb, "", doc)
 	sb.WriteString(fmt.Sprintf("type %s struct {\n", toCamelCase(tableName)))
 
 	for _, f := range fields {
+		writeDoc(&sb, "    ", f.doc)
+		if f.name == "" {
+			continue
+		}
 		sb.WriteString(fmt.Sprintf("    %-8s %-16s `gorm:\"%s\" json:\"%s\"`\n",
 			f.name, f.typeName, f.gormTags, f.jsonTag))
 	}
@@ -354,3 +757,131 @@ func buildStruct(tableName string, fields []fieldInfo) string {
 	sb.WriteString("}")
 	return sb.String()
 }
+
+// writeDoc writes text as // comment lines with the given indent
+func writeDoc(sb *strings.Builder, indent, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		sb.WriteString(strings.TrimRight(indent+"// "+strings.TrimSpace(line), " ") + "\n")
+	}
+}
+
+// postgresTypePattern captures the type name (including multi-word names),
+// the optional modifier such as (10,2) and array brackets of a lower-cased
+// Postgres column type
+var postgresTypePattern = regexp.MustCompile(`^(double precision|character varying|bit varying|` +
+	`(?:timestamp|time)(?:\s*\(\d+\))?\s+with(?:out)?\s+time\s+zone|\w+)(\s*\([^)]*\))?((?:\s*\[\d*\])*)`)
+
+// precisionPattern matches the precision of time types, e.g. timestamp(3)
+var precisionPattern = regexp.MustCompile(`\s*\(\d+\)`)
+
+// mapPostgresType maps a lower-cased Postgres column type to a Go type and
+// the gorm tags needed to round-trip it
+func mapPostgresType(sqlType string) (string, map[string]string) {
+	tags := make(map[string]string)
+	m := postgresTypePattern.FindStringSubmatch(sqlType)
+	if m == nil {
+		return "string", tags
+	}
+	baseType := strings.Join(strings.Fields(precisionPattern.ReplaceAllString(m[1], "")), " ")
+
+	if m[3] != "" {
+		tags["type"] = baseType + strings.TrimSpace(m[2]) + "[]"
+		switch baseType {
+		case "smallint", "int2", "integer", "int", "int4":
+			return "pq.Int32Array", tags
+		case "bigint", "int8":
+			return "pq.Int64Array", tags
+		case "real", "float4":
+			return "pq.Float32Array", tags
+		case "double precision", "float8", "float", "numeric", "decimal":
+			return "pq.Float64Array", tags
+		case "boolean", "bool":
+			return "pq.BoolArray", tags
+		case "bytea":
+			return "pq.ByteaArray", tags
+		default:
+			return "pq.StringArray", tags
+		}
+	}
+
+	switch baseType {
+	case "smallint", "int2":
+		return "int16", tags
+	case "smallserial", "serial2":
+		tags["autoIncrement"] = "true"
+		return "int16", tags
+	case "integer", "int", "int4":
+		return "int32", tags
+	case "serial", "serial4":
+		tags["autoIncrement"] = "true"
+		return "int32", tags
+	case "bigint", "int8":
+		return "int64", tags
+	case "bigserial", "serial8":
+		tags["autoIncrement"] = "true"
+		return "int64", tags
+	case "numeric", "decimal":
+		decimalTags(tags, strings.Trim(m[2], " ()"))
+		return "decimal.Decimal", tags
+	case "real", "float4":
+		return "float32", tags
+	case "double precision", "float8", "float":
+		return "float64", tags
+	case "boolean", "bool":
+		return "bool", tags
+	case "timestamp", "timestamptz", "timestamp with time zone", "timestamp without time zone":
+		return "mytime.DateTime", tags
+	case "uuid":
+		tags["type"] = "uuid"
+		return "string", tags
+	case "json", "jsonb":
+		tags["type"] = baseType
+		return "json.RawMessage", tags
+	case "bytea":
+		return "[]byte", tags
+	case "varchar", "character varying":
+		if size := strings.Trim(m[2], " ()"); size != "" {
+			tags["size"] = size
+		}
+		return "string", tags
+	default:
+		// text, char, citext, inet, date, time, interval, enums...
+		return "string", tags
+	}
+}
+
+// mapSQLiteType maps a lower-cased SQLite column type to a Go type
+// following SQLite's type affinity rules. Declared types that express an
+// intent SQLite has no storage class for (booleans, timestamps, decimals)
+// keep their natural Go type.
+func mapSQLiteType(sqlType string) (string, map[string]string) {
+	tags := make(map[string]string)
+	declared := sqlType
+	if i := strings.IndexByte(sqlType, '('); i >= 0 {
+		declared = strings.TrimSpace(sqlType[:i])
+	}
+
+	switch {
+	case strings.HasPrefix(declared, "bool"):
+		return "bool", tags
+	case strings.Contains(declared, "datetime") || strings.Contains(declared, "timestamp"):
+		return "mytime.DateTime", tags
+	case strings.Contains(declared, "int"):
+		return "int64", tags
+	case strings.Contains(declared, "char") || strings.Contains(declared, "clob") || strings.Contains(declared, "text"):
+		return "string", tags
+	case declared == "" || strings.Contains(declared, "blob"):
+		return "[]byte", tags
+	case strings.Contains(declared, "real") || strings.Contains(declared, "floa") || strings.Contains(declared, "doub"):
+		return "float64", tags
+	case strings.HasPrefix(declared, "decimal") || strings.HasPrefix(declared, "numeric"):
+		return "decimal.Decimal", tags
+	default:
+		// NUMERIC affinity: date, uuid and other names without a storage class
+		return "string", tags
+	}
+}
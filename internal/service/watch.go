@@ -0,0 +1,64 @@
+package service
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileStamp identifies a version of a file by modification time and size
+type FileStamp struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// ScanFiles walks roots and stamps every regular file accepted by match.
+// Hidden directories such as .git are not entered. Missing roots and
+// unreadable entries are skipped, so a snapshot can be taken while files
+// are being created or removed.
+func ScanFiles(roots []string, match func(path string) bool) map[string]FileStamp {
+	files := make(map[string]FileStamp)
+	for _, root := range roots {
+		_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path != root && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !match(path) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			files[path] = FileStamp{ModTime: info.ModTime(), Size: info.Size()}
+			return nil
+		})
+	}
+	return files
+}
+
+// ChangedFiles lists files that were added, removed or modified between two
+// snapshots taken by ScanFiles, in lexical order
+func ChangedFiles(before, after map[string]FileStamp) []string {
+	var changed []string
+	for path, stamp := range after {
+		if old, ok := before[path]; !ok || !old.ModTime.Equal(stamp.ModTime) || old.Size != stamp.Size {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
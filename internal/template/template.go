@@ -1,6 +1,7 @@
 package template
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,11 +15,16 @@ type RouterTmplData struct {
 	ApiRootDirName        string
 	HTTPMethodTags        string
 	MiddlewareTags        string
+	RoutePathTags         string
+	ControllerGroupTags   string
 	RegisterControllers   string
+	RouteBindings         string // Explicit route registrations for the static router template
 }
 
-type OnlyProjectNameData struct {
+// ProjectData holds data used to render project scaffolding templates
+type ProjectData struct {
 	ProjectName string
+	DBDialect   string // SQL dialect of the lib/db connector, e.g. mysql
 }
 
 type ControllerStructNameData struct {
@@ -32,20 +38,95 @@ type MiddlewareNameData struct {
 type ModelData struct {
 	ModelPkg        string
 	ProjectName     string
+	DBPkg           string // lib/db connector package, e.g. mysql
 	ModelStruct     string
 	ModelStructName string
+	Imports         []string // Import paths of the generated file
+	PKField         string   // Primary key field checked by Record.Exists; empty leaves out Exists and Read
+	PKType          string   // Parameter type of Record.Read
+	PKExists        string   // Condition on PKField meaning the record is stored
+	Enums           []EnumType
+	Columns         []ModelColumn
+	Finders         []ModelFinder
+	Declared        map[string]bool // Names the user-owned files declare, e.g. Query or Record.FindByEmail
+}
+
+// ModelColumn is a column constant and, when Comparable, the Query methods
+// filtering and sorting on it
+type ModelColumn struct {
+	Const      string // e.g. ColumnEmail
+	Name       string // Column name
+	Field      string
+	Type       string // Go type of the values
+	Comparable bool
+}
+
+// ModelFinder is a FindBy method of Record or List
+type ModelFinder struct {
+	Name   string
+	Unique bool   // A Record method taking one row
+	Params string // e.g. "orgId uint64, name string"
+	Conds  string // Map entries of the condition, e.g. "ColumnOrgId: orgId, ColumnName: name"
+}
+
+// EnumType is the named string type of an ENUM or SET column
+type EnumType struct {
+	Name      string
+	Column    string
+	Set       bool
+	Values    []EnumValue
+	ConstList string // Comma-separated constant names, e.g. "StatusPaid, StatusRefunded"
+}
+
+// EnumValue is a constant of an EnumType
+type EnumValue struct {
+	Const   string
+	Literal string // Quoted Go string literal
 }
 
 // CreateFile renders the provided template content with data and writes it to path.
 // The write is performed atomically by writing to a temp file in the same directory
 // and then renaming it into place. Returns any parse/execute/io error instead of panicking.
 func CreateFile(tmplContent string, data any, path string) error {
+	content, err := Render(tmplContent, data, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, content)
+}
+
+// CreateFileIfChanged renders the template like CreateFile but only writes path
+// when the rendered content differs from what is already on disk, so file
+// watchers and build caches are not disturbed by no-op regenerations.
+// It reports whether the file was written.
+func CreateFileIfChanged(tmplContent string, data any, path string) (bool, error) {
+	content, err := Render(tmplContent, data, filepath.Base(path))
+	if err != nil {
+		return false, err
+	}
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, content) {
+		return false, nil
+	}
+	return true, WriteFileAtomic(path, content)
+}
+
+// Render executes the template content with data and returns the result
+func Render(tmplContent string, data any, name string) ([]byte, error) {
 	// Parse template (do not panic on error)
-	tmpl, err := stdtmpl.New(filepath.Base(path)).Parse(tmplContent)
+	tmpl, err := stdtmpl.New(name).Parse(tmplContent)
 	if err != nil {
-		return fmt.Errorf("parse template: %w", err)
+		return nil, fmt.Errorf("parse template: %w", err)
 	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("execute template %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
 
+// WriteFileAtomic writes content to path through a temp file in the same
+// directory that is renamed into place, creating the directory if needed.
+func WriteFileAtomic(path string, content []byte) error {
 	// Ensure directory exists
 	dir := filepath.Dir(path)
 	if dir != "" {
@@ -66,10 +147,9 @@ func CreateFile(tmplContent string, data any, path string) error {
 		_ = tmpFile.Close()
 		_ = os.Remove(tmpName)
 	}
-	// Execute template into temp file
-	if err := tmpl.Execute(tmpFile, data); err != nil {
+	if _, err := tmpFile.Write(content); err != nil {
 		cleanup()
-		return fmt.Errorf("execute template to %s: %w", tmpName, err)
+		return fmt.Errorf("write temp file %s: %w", tmpName, err)
 	}
 
 	// Close before rename
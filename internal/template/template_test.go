@@ -0,0 +1,53 @@
+package template
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// testPackage runs the tests in testdata/<name>_test.go against the
+// package template tmpl, a path below templates/basic. The library
+// templates only build inside a project, so they are copied into a module
+// of their own.
+func testPackage(t *testing.T, tmpl, name string) {
+	t.Helper()
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		name + ".go":      filepath.Join("..", "..", "templates", "basic", filepath.FromSlash(tmpl)),
+		name + "_test.go": filepath.Join("testdata", name+"_test.go"),
+	}
+	for dst, src := range files {
+		content, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, dst), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mod := "module example.com/" + name + "\n\ngo 1.24\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(gobin, "test", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off", "GOPROXY=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+}
+
+func TestMigrateRunner(t *testing.T) {
+	testPackage(t, "lib/db/migrate/migrate.go.tmpl", "migrate")
+}
+
+func TestMytime(t *testing.T) {
+	testPackage(t, "lib/mytime/mytime.go.tmpl", "mytime")
+}
@@ -0,0 +1,49 @@
+package migrate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		dialect string
+		src     string
+		want    []string
+	}{
+		{"mysql", "CREATE TABLE a (id int);\n\nCREATE TABLE b (id int)", []string{"CREATE TABLE a (id int)", "CREATE TABLE b (id int)"}},
+		{"mysql", "INSERT INTO a VALUES ('x;y', \"it\\\"s;\", `c;d`);", []string{"INSERT INTO a VALUES ('x;y', \"it\\\"s;\", `c;d`)"}},
+		{"mysql", "-- a; b\n# c; d\n/* e; f */;\nSELECT 1;", []string{"SELECT 1"}},
+		{"postgres", "SELECT 'a\\';SELECT 2", []string{"SELECT 'a\\'", "SELECT 2"}},
+		{"postgres", "CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql;\nSELECT $1;",
+			[]string{"CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql", "SELECT $1"}},
+		{"postgres", "DO $$ BEGIN PERFORM 1; END $$;", []string{"DO $$ BEGIN PERFORM 1; END $$"}},
+		{"sqlite", "SELECT '$$;'; SELECT 2;", []string{"SELECT '$$;'", "SELECT 2"}},
+		{"sqlite", "SELECT # 1;", []string{"SELECT # 1"}},
+	}
+	for _, tt := range tests {
+		if got := SplitStatements(tt.src, tt.dialect); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitStatements(%q, %s)\n got %q\nwant %q", tt.src, tt.dialect, got, tt.want)
+		}
+	}
+}
+
+func TestDollarTag(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"$$ body $$", "$$"},
+		{"$body$ x $body$", "$body$"},
+		{"$_a1$", "$_a1$"},
+		{"$1", ""},
+		{"$1$", ""},
+		{"$a b$", ""},
+		{"$", ""},
+	}
+	for _, tt := range tests {
+		if got := dollarTag(tt.src); got != tt.want {
+			t.Errorf("dollarTag(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
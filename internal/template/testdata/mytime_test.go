@@ -0,0 +1,114 @@
+package mytime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateTimeScan(t *testing.T) {
+	datetime := time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local)
+	date := time.Date(2024, 5, 6, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		value  any
+		want   time.Time
+		isDate bool
+	}{
+		{datetime, datetime, false},
+		{"2024-05-06 07:08:09", datetime, false},
+		{[]byte("2024-05-06 07:08:09"), datetime, false},
+		{"2024-05-06 07:08:09.5", datetime.Add(500 * time.Millisecond), false},
+		{datetime.UTC().Format(time.RFC3339), datetime, false},
+		{"2024-05-06", date, true},
+		{[]byte("2024-05-06"), date, true},
+		{nil, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		var dt DateTime
+		if err := dt.Scan(tt.value); err != nil {
+			t.Errorf("Scan(%v): %v", tt.value, err)
+			continue
+		}
+		if !dt.Equal(tt.want) || dt.IsDate() != tt.isDate {
+			t.Errorf("Scan(%v) = %v, date %v; want %v, date %v", tt.value, dt.Time, dt.IsDate(), tt.want, tt.isDate)
+		}
+	}
+
+	for _, value := range []any{"06/05/2024", 20240506} {
+		var dt DateTime
+		if err := dt.Scan(value); err == nil {
+			t.Errorf("Scan(%v) = %v, want an error", value, dt)
+		}
+	}
+}
+
+func TestDateTimeRoundTrip(t *testing.T) {
+	for _, in := range []DateTime{
+		NewFromTime(time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local)),
+		{Time: time.Date(2024, 5, 6, 0, 0, 0, 0, time.Local), isDate: true},
+	} {
+		value, err := in.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out DateTime
+		if err := out.Scan(value); err != nil {
+			t.Fatalf("Scan(%v): %v", value, err)
+		}
+		if !out.Equal(in.Time) {
+			t.Errorf("%v read back as %v", in, out)
+		}
+		// the text form a driver may return instead of a time.Time
+		if err := out.Scan(in.String()); err != nil || !out.Equal(in.Time) || out.IsDate() != in.IsDate() {
+			t.Errorf("%q read back as %v, date %v (%v)", in.String(), out, out.IsDate(), err)
+		}
+	}
+}
+
+func TestDateTimeScanResetsDate(t *testing.T) {
+	var dt DateTime
+	if err := dt.Scan("2024-05-06"); err != nil || !dt.IsDate() {
+		t.Fatalf("Scan of a date: date %v (%v)", dt.IsDate(), err)
+	}
+	for _, value := range []any{"2024-05-06 07:08:09", time.Now()} {
+		if err := dt.Scan(value); err != nil {
+			t.Fatal(err)
+		}
+		if dt.IsDate() {
+			t.Errorf("Scan(%v) after a date keeps the date flag", value)
+		}
+		dt.SetIsDate(true)
+	}
+}
+
+func TestNullDateTimeRoundTrip(t *testing.T) {
+	valid := NewNullDateTime(time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local))
+	value, err := valid.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nt NullDateTime
+	if err := nt.Scan(value); err != nil {
+		t.Fatal(err)
+	}
+	if !nt.Valid || !nt.Equal(valid.Time) {
+		t.Errorf("%v read back as %v, valid %v", valid, nt, nt.Valid)
+	}
+
+	// scanning NULL into the same value clears it
+	if value, err = (NullDateTime{}).Value(); err != nil || value != nil {
+		t.Fatalf("Value of NULL = %v, %v; want nil", value, err)
+	}
+	if err := nt.Scan(value); err != nil {
+		t.Fatal(err)
+	}
+	if nt.Valid || !nt.IsZero() || nt.String() != "" {
+		t.Errorf("NULL read back as %v, valid %v", nt.Time, nt.Valid)
+	}
+
+	if err := nt.Scan([]byte("2024-05-06")); err != nil {
+		t.Fatal(err)
+	}
+	if !nt.Valid || !nt.IsDate() || nt.String() != "2024-05-06" {
+		t.Errorf("date read back as %q, valid %v", nt, nt.Valid)
+	}
+}